@@ -1,12 +1,19 @@
 package main
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
 	"runtime"
+	"time"
 
+	"github.com/jnsgruk/releasegen/internal/credentials"
+	"github.com/jnsgruk/releasegen/internal/launchpad"
 	"github.com/jnsgruk/releasegen/internal/releasegen"
+	"github.com/jnsgruk/releasegen/internal/repos"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -33,7 +40,7 @@ For more details on the configuration format, see the homepage below.
 
 Prior to launching, you must also set an environment variable named RELEASEGEN_TOKEN whose
 contents is a Github Personal Access token with sufficient rights over any org you wish to
-query. 
+query, unless every org is configured with its own credential or to authenticate as a Github App.
 
 For example:
 
@@ -45,6 +52,41 @@ Homepage: https://github.com/jnsgruk/releasegen
 `
 )
 
+// Exit codes returned by releasegen, so that callers can distinguish between failure modes.
+const (
+	exitError          = 1
+	exitConfigError    = 2
+	exitAuthError      = 3
+	exitPartialFailure = 4
+	exitTotalFailure   = 5
+	exitStale          = 6
+)
+
+// configError indicates that releasegen could not run due to its configuration or environment.
+// The stage is either repos.StageConfig or repos.StageCredentials.
+type configError struct {
+	error
+	stage string
+}
+
+// exitCode returns the process exit code that corresponds to the error.
+func exitCode(err error) int {
+	switch {
+	case errors.As(err, &configError{}):
+		return exitConfigError
+	case errors.Is(err, releasegen.ErrAuth):
+		return exitAuthError
+	case errors.Is(err, releasegen.ErrPartialFailure):
+		return exitPartialFailure
+	case errors.Is(err, releasegen.ErrTotalFailure):
+		return exitTotalFailure
+	case errors.Is(err, releasegen.ErrStale):
+		return exitStale
+	default:
+		return exitError
+	}
+}
+
 // buildVersion writes a multiline version string from the specified version variables.
 func buildVersion(version, commit, date string) string {
 	result := version
@@ -62,7 +104,246 @@ func buildVersion(version, commit, date string) string {
 	return result
 }
 
+// buildInfo describes the build of releasegen, for the report envelope.
+func buildInfo() releasegen.BuildInfo {
+	return releasegen.BuildInfo{Version: version, Commit: commit, Date: date}
+}
+
+// overrideOutput overrides the forms that release bodies and commit messages are output in, so
+// that reports for different consumers can be generated from the same config.
+func overrideOutput(conf *releasegen.Config, output string) error {
+	conf.HTML.Output = output
+
+	if err := conf.HTML.Validate(); err != nil {
+		return configError{fmt.Errorf("invalid --output: %w", err), repos.StageConfig}
+	}
+
+	return nil
+}
+
+// loadConfig reads and parses the releasegen config file, and sets the Github token from the
+// environment. The token is only required if an org isn't configured to use a Github App or a
+// credential of its own.
+func loadConfig() (*releasegen.Config, error) {
+	err := viper.ReadInConfig()
+	if err != nil {
+		if errors.As(err, &viper.ConfigFileNotFoundError{}) {
+			return nil, configError{errors.New("no config file found, see 'releasegen --help' for details"), repos.StageConfig}
+		}
+
+		return nil, configError{errors.New("error parsing releasegen config file"), repos.StageConfig}
+	}
+
+	conf := &releasegen.Config{}
+
+	err = viper.Unmarshal(conf)
+	if err != nil {
+		return nil, configError{errors.New("error parsing releasegen config file"), repos.StageConfig}
+	}
+
+	err = conf.Validate()
+	if err != nil {
+		return nil, configError{fmt.Errorf("error parsing releasegen config file: %w", err), repos.StageConfig}
+	}
+
+	ghToken := viper.GetString("token")
+	if ghToken == "" && conf.NeedsGithubToken() {
+		return nil, configError{errors.New("environment variable RELEASEGEN_TOKEN not set"), repos.StageConfig}
+	}
+
+	credentials.Register(ghToken)
+
+	conf.SetGithubToken(ghToken)
+
+	err = conf.ResolveCredentials()
+	if err != nil {
+		return nil, configError{err, repos.StageCredentials}
+	}
+
+	return conf, nil
+}
+
+// launchpadCmd returns the 'launchpad' command, and its 'login' subcommand which obtains OAuth
+// credentials for the Launchpad API and stores them in a file.
+func launchpadCmd() *cobra.Command {
+	var output, consumerKey string
+
+	cmd := &cobra.Command{
+		Use:   "launchpad",
+		Short: "Commands for working with Launchpad",
+	}
+
+	loginCmd := &cobra.Command{
+		Use:   "login",
+		Short: "Authorise releasegen to read private Launchpad projects",
+		Long: `Authorise releasegen to read private Launchpad projects.
+
+This requests an OAuth token from Launchpad, which must be authorised in a web browser. The
+resulting credential is written to a file, which can be referenced from the 'credential' key
+of a team's Launchpad configuration.`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			requestToken, err := launchpad.RequestToken(ctx, consumerKey)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Visit the following URL to authorise releasegen:\n\n  %s\n\n",
+				requestToken.AuthorizeURL())
+			fmt.Fprint(cmd.OutOrStdout(), "Press Enter once you have authorised releasegen...")
+
+			_, _ = bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+
+			accessToken, err := requestToken.Exchange(ctx)
+			if err != nil {
+				return err
+			}
+
+			err = os.MkdirAll(filepath.Dir(output), 0o700)
+			if err != nil {
+				return fmt.Errorf("error creating directory for launchpad credential: %w", err)
+			}
+
+			err = os.WriteFile(output, []byte(accessToken.String()+"\n"), 0o600)
+			if err != nil {
+				return fmt.Errorf("error writing launchpad credential: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "\nCredential written to %s. Add it to your config with:\n\n", output)
+			fmt.Fprintf(cmd.OutOrStdout(), "  launchpad:\n    credential:\n      file: %s\n", output)
+
+			return nil
+		},
+	}
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		configDir = "."
+	}
+
+	loginCmd.Flags().StringVarP(&output, "output", "o", filepath.Join(configDir, "releasegen", "launchpad-credential"),
+		"file to write the launchpad credential to")
+	loginCmd.Flags().StringVar(&consumerKey, "consumer-key", launchpad.DefaultConsumerKey,
+		"oauth consumer key used to identify releasegen to launchpad")
+
+	cmd.AddCommand(loginCmd)
+
+	return cmd
+}
+
+// staleCmd returns the 'stale' command, which lists the repositories in a report that are due a
+// release, and fails if there are more than allowed.
+func staleCmd() *cobra.Command {
+	var (
+		input, format string
+		maxFindings   int
+		opts          releasegen.StaleOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "stale",
+		Short: "List repositories that are due a release",
+		Long: `List repositories that are due a release.
+
+Repositories are checked for unreleased commits that are too old, and for releases that are too
+old. Snaps are checked for stable channels that lag their edge channel, and charms for a missing
+stable channel. A threshold of zero disables its check.
+
+The report is read from the file given with --input, or '-' for stdin, which may be output with or
+without --envelope. Otherwise, a new report is generated using the config file.`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "table" && format != "json" {
+				return configError{fmt.Errorf("unknown output format '%s'", format), repos.StageConfig}
+			}
+
+			report, err := staleInput(cmd, input)
+			if err != nil {
+				return err
+			}
+
+			now := report.GeneratedAt
+			if now.IsZero() {
+				now = time.Now().UTC()
+			}
+
+			findings := releasegen.FindStale(report.Teams, opts, now)
+
+			if format == "json" {
+				err = findings.JSON(cmd.OutOrStdout())
+			} else {
+				err = findings.Print(cmd.OutOrStdout())
+			}
+
+			if err != nil {
+				return fmt.Errorf("error printing stale repositories: %w", err)
+			}
+
+			if len(findings) > maxFindings {
+				return fmt.Errorf("%w: found %d, allowed %d", releasegen.ErrStale, len(findings), maxFindings)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&input, "input", "i", "", "read the report from a file, or '-' for stdin")
+	cmd.Flags().StringVarP(&format, "format", "f", "table", "output format, either 'table' or 'json'")
+	cmd.Flags().IntVar(&maxFindings, "max", 0, "the number of findings allowed before exiting non-zero")
+	cmd.Flags().IntVar(&opts.UnreleasedDays, "unreleased-days", 30,
+		"flag unreleased commits older than this many days")
+	cmd.Flags().IntVar(&opts.ReleaseMonths, "release-months", 6,
+		"flag repositories with no release or tag in this many months")
+	cmd.Flags().IntVar(&opts.LagRevisions, "lag-revisions", 10,
+		"flag snaps whose stable channel is more than this many revisions behind edge")
+	cmd.Flags().IntVar(&opts.LagDays, "lag-days", 30,
+		"flag snaps whose stable channel is more than this many days behind edge")
+
+	return cmd
+}
+
+// staleInput returns the report checked by the 'stale' command, which is read from a file or
+// stdin, or generated using the config file if no input is given.
+func staleInput(cmd *cobra.Command, input string) (*releasegen.Report, error) {
+	switch input {
+	case "":
+		conf, err := loadConfig()
+		if err != nil {
+			return nil, err
+		}
+
+		report := releasegen.GenerateReport(conf)
+
+		// As with the report itself, an incomplete report is only rejected under fail-fast.
+		err = report.Evaluate(conf.FailurePolicy)
+		if err != nil && conf.FailurePolicy.Mode == releasegen.FailFast {
+			return nil, err
+		}
+
+		if err != nil {
+			log.Println(err.Error())
+		}
+
+		return report, nil
+	case "-":
+		return releasegen.ReadReport(cmd.InOrStdin())
+	default:
+		f, err := os.Open(input)
+		if err != nil {
+			return nil, fmt.Errorf("error opening report: %w", err)
+		}
+		defer f.Close()
+
+		return releasegen.ReadReport(f)
+	}
+}
+
 func main() {
+	// Ensure that secrets never appear in log output or error messages.
+	log.SetOutput(credentials.NewRedactingWriter(os.Stderr))
+
 	// Set the default config file name/type.
 	viper.SetConfigName("releasegen")
 	viper.SetConfigType("yaml")
@@ -76,6 +357,11 @@ func main() {
 	viper.SetEnvPrefix("releasegen")
 	viper.MustBindEnv("token")
 
+	var (
+		envelope bool
+		output   string
+	)
+
 	rootCmd := &cobra.Command{
 		Use:          "releasegen",
 		Version:      buildVersion(version, commit, date),
@@ -83,36 +369,88 @@ func main() {
 		Long:         longDesc,
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			err := viper.ReadInConfig()
+			conf, err := loadConfig()
+			if err == nil && output != "" {
+				err = overrideOutput(conf, output)
+			}
+
 			if err != nil {
-				if errors.As(err, &viper.ConfigFileNotFoundError{}) {
-					return errors.New("no config file found, see 'releasegen --help' for details")
+				// The envelope records the failure, so that consumers can tell the run failed.
+				var cErr configError
+				if envelope && errors.As(err, &cErr) {
+					releasegen.FailedReport(cErr.stage, err).Envelope(buildInfo()).Dump()
+				}
+
+				return err
+			}
+
+			report := releasegen.GenerateReport(conf)
+			result := report.Evaluate(conf.FailurePolicy)
+
+			// Under the fail-fast policy, an incomplete report is never output. The envelope is
+			// still output without any teams, so that the errors of the run are recorded.
+			if result != nil && conf.FailurePolicy.Mode == releasegen.FailFast {
+				if envelope {
+					report.Teams = releasegen.ReleaseReport{}
+					report.Envelope(buildInfo()).Dump()
 				}
 
-				return errors.New("error parsing releasegen config file")
+				return result
+			}
+
+			if envelope {
+				report.Envelope(buildInfo()).Dump()
+			} else {
+				report.Teams.Dump()
 			}
 
-			conf := &releasegen.Config{}
+			return result
+		},
+	}
 
-			err = viper.Unmarshal(conf)
+	rootCmd.Flags().BoolVar(&envelope, "envelope", false,
+		"wrap the report in an envelope containing metadata about the run")
+	rootCmd.Flags().StringVar(&output, "output", "",
+		"output release bodies as 'both', 'html' or 'markdown', overriding the config")
+
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Commands for working with the releasegen config file",
+	}
+
+	checkCmd := &cobra.Command{
+		Use:          "check",
+		Short:        "Check that the orgs, teams and project groups in the config exist",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			conf, err := loadConfig()
 			if err != nil {
-				return errors.New("error parsing releasegen config file")
+				return err
 			}
 
-			ghToken := viper.GetString("token")
-			if ghToken == "" {
-				return errors.New("environment variable RELEASEGEN_TOKEN not set")
+			report := releasegen.CheckConfig(conf)
+
+			err = report.Print(cmd.OutOrStdout())
+			if err != nil {
+				return fmt.Errorf("error printing config check results: %w", err)
 			}
 
-			conf.SetGithubToken(ghToken)
-			teams := releasegen.GenerateReport(conf)
-			teams.Dump()
+			if report.Failed() {
+				return errors.New("config check found errors")
+			}
 
 			return nil
 		},
 	}
 
+	configCmd.AddCommand(checkCmd)
+	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(launchpadCmd())
+	rootCmd.AddCommand(staleCmd())
+	rootCmd.SetErr(credentials.NewRedactingWriter(os.Stderr))
+
 	if err := rootCmd.Execute(); err != nil {
-		log.Fatalln(err.Error())
+		log.Println(err.Error())
+		os.Exit(exitCode(err))
 	}
 }
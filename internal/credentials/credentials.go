@@ -0,0 +1,151 @@
+package credentials
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// resolveTimeout bounds how long a credential provider, such as a command, may take to run.
+const resolveTimeout = 30 * time.Second
+
+var (
+	// errInvalidRef is returned when a credential reference doesn't specify exactly one source.
+	errInvalidRef = errors.New(
+		"credential must specify exactly one of 'env', 'file', 'systemd', 'git', 'command' or 'name'",
+	)
+	// errEmptyCredential is returned when a credential resolves to an empty string.
+	errEmptyCredential = errors.New("credential is empty")
+)
+
+// Ref refers to a secret held by one of the supported providers, or by the name of a credential
+// defined in the 'credentials' section of the config.
+type Ref struct {
+	// Env is the name of an environment variable containing the secret.
+	Env string `mapstructure:"env"`
+	// File is the path of a file containing the secret, such as a Kubernetes secret mount.
+	File string `mapstructure:"file"`
+	// Systemd is the name of a credential passed by systemd's LoadCredential= option.
+	Systemd string `mapstructure:"systemd"`
+	// Git is a URL for which the secret is retrieved using 'git credential fill'.
+	Git string `mapstructure:"git"`
+	// Command is a command and its arguments, whose output is the secret.
+	Command []string `mapstructure:"command"`
+	// Name is the name of a credential in the 'credentials' section of the config.
+	Name string `mapstructure:"name"`
+}
+
+// Provider retrieves a secret from a single kind of source.
+type Provider interface {
+	Secret(ctx context.Context) (string, error)
+}
+
+// Validate checks that the reference specifies exactly one source for the credential.
+func (r *Ref) Validate() error {
+	sources := 0
+
+	for _, s := range []string{r.Env, r.File, r.Systemd, r.Git, strings.Join(r.Command, " "), r.Name} {
+		if s != "" {
+			sources++
+		}
+	}
+
+	if sources != 1 {
+		return errInvalidRef
+	}
+
+	return nil
+}
+
+// Resolve returns the secret that the reference points to. References by name are looked up in
+// named, whose keys are lower case as config keys are case insensitive. The secret is registered
+// for redaction before it is returned.
+func (r *Ref) Resolve(named map[string]Ref) (string, error) {
+	if err := r.Validate(); err != nil {
+		return "", err
+	}
+
+	if r.Name != "" {
+		ref, ok := named[strings.ToLower(r.Name)]
+		if !ok {
+			return "", fmt.Errorf("no credential named '%s' in config", r.Name)
+		}
+
+		// Named credentials can't refer to other named credentials.
+		if ref.Name != "" {
+			return "", fmt.Errorf("credential '%s' can't refer to another named credential", r.Name)
+		}
+
+		if err := ref.Validate(); err != nil {
+			return "", fmt.Errorf("credential '%s': %w", r.Name, err)
+		}
+
+		return ref.resolve(r.Name)
+	}
+
+	return r.resolve("")
+}
+
+// resolve returns the secret from the reference's provider. The name of the credential, if it
+// was referred to by name, is included in errors along with the provider.
+func (r *Ref) resolve(name string) (string, error) {
+	source := r.String()
+	if name != "" {
+		source = fmt.Sprintf("'%s' (%s)", name, source)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+
+	secret, err := r.provider().Secret(ctx)
+	if err != nil {
+		return "", fmt.Errorf("error reading credential %s: %w", source, err)
+	}
+
+	secret = strings.TrimSpace(secret)
+	if secret == "" {
+		return "", fmt.Errorf("%w: %s", errEmptyCredential, source)
+	}
+
+	Register(secret)
+
+	return secret, nil
+}
+
+// String describes the source of the credential, without its secret, for use in errors.
+func (r *Ref) String() string {
+	switch {
+	case r.Name != "":
+		return fmt.Sprintf("name '%s'", r.Name)
+	case r.Env != "":
+		return fmt.Sprintf("env '%s'", r.Env)
+	case r.File != "":
+		return fmt.Sprintf("file '%s'", r.File)
+	case r.Systemd != "":
+		return fmt.Sprintf("systemd '%s'", r.Systemd)
+	case r.Git != "":
+		return fmt.Sprintf("git '%s'", r.Git)
+	case len(r.Command) > 0:
+		return fmt.Sprintf("command '%s'", r.Command[0])
+	default:
+		return "no source"
+	}
+}
+
+// provider returns the Provider for the source specified in the reference.
+func (r *Ref) provider() Provider {
+	switch {
+	case r.Env != "":
+		return envProvider(r.Env)
+	case r.File != "":
+		return fileProvider(r.File)
+	case r.Systemd != "":
+		return systemdProvider(r.Systemd)
+	case r.Git != "":
+		return gitProvider(r.Git)
+	default:
+		return commandProvider(r.Command)
+	}
+}
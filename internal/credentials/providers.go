@@ -0,0 +1,102 @@
+package credentials
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// envProvider reads a secret from the named environment variable.
+type envProvider string
+
+// Secret returns the value of the environment variable.
+func (p envProvider) Secret(_ context.Context) (string, error) {
+	value, ok := os.LookupEnv(string(p))
+	if !ok {
+		return "", fmt.Errorf("environment variable '%s' not set", string(p))
+	}
+
+	return value, nil
+}
+
+// fileProvider reads a secret from a file, such as a Kubernetes secret mount.
+type fileProvider string
+
+// Secret returns the contents of the file.
+func (p fileProvider) Secret(_ context.Context) (string, error) {
+	contents, err := os.ReadFile(string(p))
+	if err != nil {
+		return "", fmt.Errorf("error reading credential file: %w", err)
+	}
+
+	return string(contents), nil
+}
+
+// systemdProvider reads a secret passed to the service using systemd's LoadCredential= option.
+type systemdProvider string
+
+// Secret returns the contents of the named credential in $CREDENTIALS_DIRECTORY.
+func (p systemdProvider) Secret(ctx context.Context) (string, error) {
+	dir := os.Getenv("CREDENTIALS_DIRECTORY")
+	if dir == "" {
+		return "", errors.New("CREDENTIALS_DIRECTORY not set, is releasegen running under systemd?")
+	}
+
+	return fileProvider(filepath.Join(dir, string(p))).Secret(ctx)
+}
+
+// gitProvider retrieves a secret for a URL from the configured git credential helpers.
+type gitProvider string
+
+// Secret returns the password returned by 'git credential fill' for the URL.
+func (p gitProvider) Secret(ctx context.Context) (string, error) {
+	u, err := url.Parse(string(p))
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("invalid url for git credential: %s", string(p))
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "credential", "fill")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("protocol=%s\nhost=%s\npath=%s\n\n", u.Scheme, u.Host, u.Path))
+	// Fail rather than prompt if no credential helper can provide the credential.
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("error running 'git credential fill': %w", err)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		if password, ok := strings.CutPrefix(scanner.Text(), "password="); ok {
+			return password, nil
+		}
+	}
+
+	return "", fmt.Errorf("no password returned by 'git credential fill' for %s", u.Host)
+}
+
+// commandProvider runs a command, such as a password manager CLI, whose output is the secret.
+type commandProvider []string
+
+// Secret returns the standard output of the command.
+func (p commandProvider) Secret(ctx context.Context) (string, error) {
+	cmd := exec.CommandContext(ctx, p[0], p[1:]...) //nolint:gosec
+
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("error running credential command '%s': %w: %s",
+			p[0], err, Redact(strings.TrimSpace(stderr.String())))
+	}
+
+	return string(output), nil
+}
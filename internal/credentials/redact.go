@@ -0,0 +1,61 @@
+package credentials
+
+import (
+	"io"
+	"strings"
+	"sync"
+)
+
+// redacted replaces secrets in redacted output.
+const redacted = "[REDACTED]"
+
+//nolint:gochecknoglobals
+var (
+	// secrets holds every secret resolved during this run, so they can be redacted.
+	secrets   = []string{}
+	secretsMu sync.RWMutex
+)
+
+// Register adds a secret to the list of secrets that are removed by Redact.
+func Register(secret string) {
+	if secret == "" {
+		return
+	}
+
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+
+	secrets = append(secrets, secret)
+}
+
+// Redact replaces any registered secrets in s.
+func Redact(s string) string {
+	secretsMu.RLock()
+	defer secretsMu.RUnlock()
+
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+
+	return s
+}
+
+// redactingWriter is an io.Writer that redacts secrets before writing to the underlying writer.
+type redactingWriter struct {
+	w io.Writer
+}
+
+// NewRedactingWriter returns a writer that redacts registered secrets from everything written to
+// it, such as log output, before passing it on to w.
+func NewRedactingWriter(w io.Writer) io.Writer {
+	return &redactingWriter{w: w}
+}
+
+// Write redacts secrets from p and writes it to the underlying writer.
+func (rw *redactingWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(rw.w, Redact(string(p))); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
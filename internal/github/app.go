@@ -0,0 +1,200 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	gh "github.com/google/go-github/v54/github"
+	"github.com/jnsgruk/releasegen/internal/credentials"
+	"golang.org/x/oauth2"
+)
+
+// appJWTLifetime is how long the JWTs used to authenticate as a Github App are valid for. Github
+// rejects JWTs that expire more than ten minutes in the future.
+const appJWTLifetime = 9 * time.Minute
+
+// AppConfig contains fields used in releasegen's config.yaml file to authenticate with Github as
+// a Github App installation, rather than with a personal access token.
+type AppConfig struct {
+	ID             int64            `mapstructure:"id"`
+	PrivateKey     *credentials.Ref `mapstructure:"private-key"`
+	InstallationID int64            `mapstructure:"installation-id"`
+
+	key *rsa.PrivateKey
+}
+
+// Validate checks that the Github App configuration is complete.
+func (ac *AppConfig) Validate() error {
+	if ac.ID == 0 {
+		return errors.New("github app id must be set")
+	}
+
+	if ac.PrivateKey == nil {
+		return errors.New("github app private-key must be set")
+	}
+
+	if err := ac.PrivateKey.Validate(); err != nil {
+		return fmt.Errorf("invalid github app private-key: %w", err)
+	}
+
+	return nil
+}
+
+// SetPrivateKey sets the app's private key from the PEM encoded key that its PrivateKey
+// credential resolved to.
+func (ac *AppConfig) SetPrivateKey(s string) error {
+	key, err := parsePrivateKey(s)
+	if err != nil {
+		return err
+	}
+
+	ac.key = key
+
+	return nil
+}
+
+// appTokenSource is an oauth2.TokenSource that issues installation access tokens for a Github
+// App. If no installation ID is configured, the installation for the org is discovered.
+type appTokenSource struct {
+	app       AppConfig
+	org       string
+	baseURL   string
+	uploadURL string
+
+	installationID int64
+}
+
+// newAppTokenSource returns a token source that issues installation tokens for the given app,
+// reusing each token until shortly before it expires. The returned source is safe for
+// concurrent use. The base and upload URLs identify a Github Enterprise Server instance, and
+// may be empty for github.com.
+func newAppTokenSource(app AppConfig, org, baseURL, uploadURL string) oauth2.TokenSource {
+	return oauth2.ReuseTokenSource(nil, &appTokenSource{
+		app:            app,
+		org:            org,
+		baseURL:        baseURL,
+		uploadURL:      uploadURL,
+		installationID: app.InstallationID,
+	})
+}
+
+// Token creates a new installation access token for the Github App.
+func (s *appTokenSource) Token() (*oauth2.Token, error) {
+	ctx := context.Background()
+
+	jwt, err := s.signJWT()
+	if err != nil {
+		return nil, err
+	}
+
+	jwtClient := oauth2.NewClient(ctx, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: jwt}))
+
+	appClient, err := newClient(jwtClient, s.baseURL, s.uploadURL)
+	if err != nil {
+		return nil, fmt.Errorf("error creating github app client: %w", err)
+	}
+
+	// Discover the app's installation for the org if one wasn't configured.
+	if s.installationID == 0 {
+		installation, res, err := appClient.Apps.FindOrganizationInstallation(ctx, s.org)
+		if err != nil {
+			return nil, fmt.Errorf("error finding github app installation for org '%s': %w", s.org, appError(res, err))
+		}
+
+		s.installationID = installation.GetID()
+	}
+
+	token, res, err := appClient.Apps.CreateInstallationToken(ctx, s.installationID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating github app installation token: %w", appError(res, err))
+	}
+
+	credentials.Register(token.GetToken())
+
+	return &oauth2.Token{AccessToken: token.GetToken(), Expiry: token.GetExpiresAt().Time}, nil
+}
+
+// appError wraps an error from authenticating as the Github App with ErrUnauthorized if Github
+// rejected the app's credentials, such as a revoked or mismatched private key. The error reaches
+// the caller through the oauth2 transport without a response, so checkResponse can't classify it.
+func appError(res *gh.Response, err error) error {
+	if res != nil && (res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden) {
+		return fmt.Errorf("%w: %w", ErrUnauthorized, err)
+	}
+
+	return err
+}
+
+// signJWT creates a JWT signed with the app's private key, used to authenticate as the app.
+func (s *appTokenSource) signJWT() (string, error) {
+	if s.app.key == nil {
+		return "", errors.New("github app private key has not been resolved")
+	}
+
+	// Backdate the issue time to allow for clock drift between this machine and Github.
+	now := time.Now()
+	header := map[string]string{"alg": "RS256", "typ": "JWT"}
+	claims := map[string]int64{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": s.app.ID,
+	}
+
+	segments := []string{}
+
+	for _, part := range []any{header, claims} {
+		encoded, err := json.Marshal(part)
+		if err != nil {
+			return "", fmt.Errorf("error encoding github app jwt: %w", err)
+		}
+
+		segments = append(segments, base64.RawURLEncoding.EncodeToString(encoded))
+	}
+
+	unsigned := strings.Join(segments, ".")
+	digest := sha256.Sum256([]byte(unsigned))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.app.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("error signing github app jwt: %w", err)
+	}
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parsePrivateKey parses a PEM encoded RSA private key, as downloaded from Github.
+func parsePrivateKey(s string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(s))
+	if block == nil {
+		return nil, errors.New("error decoding github app private key: no PEM data found")
+	}
+
+	// Github issues PKCS#1 keys, but accept PKCS#8 in case the key has been converted.
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing github app private key: %w", err)
+	}
+
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("error parsing github app private key: not an RSA key")
+	}
+
+	return key, nil
+}
@@ -0,0 +1,85 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	gh "github.com/google/go-github/v54/github"
+)
+
+var (
+	// ErrNotFound is returned when a Github org, team or repository does not exist, or is not
+	// visible with the configured credentials.
+	ErrNotFound = errors.New("not found or not visible with the provided token")
+	// ErrUnauthorized is returned when the Github API rejects the configured credentials, or
+	// they don't grant access to a resource.
+	ErrUnauthorized = errors.New("github credentials are invalid, revoked or lack permission")
+)
+
+// CheckOrg verifies that the configured Github org, or user, exists and is readable.
+func (oc *OrgConfig) CheckOrg(ctx context.Context) error {
+	client, err := oc.GithubClient()
+	if err != nil {
+		return err
+	}
+
+	var res *gh.Response
+	if oc.User {
+		_, res, err = client.Users.Get(ctx, oc.Org)
+	} else {
+		_, res, err = client.Organizations.Get(ctx, oc.Org)
+	}
+
+	return checkResponse(res, err)
+}
+
+// CheckTeam verifies that the team slug exists in the configured Github org and is readable.
+func (oc *OrgConfig) CheckTeam(ctx context.Context, slug string) error {
+	client, err := oc.GithubClient()
+	if err != nil {
+		return err
+	}
+
+	_, res, err := client.Teams.GetTeamBySlug(ctx, oc.Org, slug)
+
+	return checkResponse(res, err)
+}
+
+// CheckRepo verifies that the named repository exists in the configured Github org.
+func (oc *OrgConfig) CheckRepo(ctx context.Context, name string) error {
+	client, err := oc.GithubClient()
+	if err != nil {
+		return err
+	}
+
+	_, res, err := client.Repositories.Get(ctx, oc.Org, name)
+
+	return checkResponse(res, err)
+}
+
+// checkResponse converts the result of a Github API call into either nil, ErrNotFound,
+// ErrUnauthorized, or a descriptive error. Github also responds with 403 when rate limited, which
+// isn't an authentication failure.
+func checkResponse(res *gh.Response, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if res != nil && res.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+
+	var rateErr *gh.RateLimitError
+
+	var abuseErr *gh.AbuseRateLimitError
+
+	rateLimited := errors.As(err, &rateErr) || errors.As(err, &abuseErr)
+
+	if res != nil && (res.StatusCode == http.StatusUnauthorized || (res.StatusCode == http.StatusForbidden && !rateLimited)) {
+		return ErrUnauthorized
+	}
+
+	return fmt.Errorf("error querying github api: %w", err)
+}
@@ -2,35 +2,182 @@ package github
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/url"
 
 	gh "github.com/google/go-github/v54/github"
+	"github.com/jnsgruk/releasegen/internal/credentials"
+	"github.com/jnsgruk/releasegen/internal/markup"
+	"github.com/jnsgruk/releasegen/internal/repos"
 	"golang.org/x/oauth2"
 )
 
 // OrgConfig contains fields used in releasegen's config.yaml file to configure
 // its behaviour when generating reports about Github repositories.
 type OrgConfig struct {
-	Org          string   `mapstructure:"org"`
-	Teams        []string `mapstructure:"teams"`
-	IgnoredRepos []string `mapstructure:"ignores"`
+	Org          string           `mapstructure:"org"`
+	User         bool             `mapstructure:"user"`
+	Teams        []string         `mapstructure:"teams"`
+	AllRepos     bool             `mapstructure:"all-repos"`
+	Topics       []string         `mapstructure:"topics"`
+	NamePatterns []string         `mapstructure:"name-patterns"`
+	Repos        []string         `mapstructure:"repos"`
+	IgnoredRepos []string         `mapstructure:"ignores"`
+	Policy       RepoPolicy       `mapstructure:"policy"`
+	App          *AppConfig       `mapstructure:"app"`
+	BaseURL      string           `mapstructure:"base-url"`
+	UploadURL    string           `mapstructure:"upload-url"`
+	Credential   *credentials.Ref `mapstructure:"credential"`
 
 	ghClient *gh.Client
 	token    string
+	renderer *markup.Renderer
+
+	// namePatterns and ignores are the compiled NamePatterns and IgnoredRepos, set by Validate.
+	namePatterns repos.Patterns
+	ignores      repos.Patterns
+
+	maxUnreleased *int
+	artifacts     repos.ArtifactConfigs
+}
+
+// Validate checks that the repository selectors and policy, Github App, credential and Github
+// Enterprise Server configuration for the org is well formed, and compiles its name patterns
+// and ignores.
+func (oc *OrgConfig) Validate() error {
+	if oc.User && len(oc.Teams) > 0 {
+		return fmt.Errorf("github user '%s' can't specify teams", oc.Org)
+	}
+
+	var err error
+
+	oc.namePatterns, err = repos.CompilePatterns(oc.NamePatterns)
+	if err != nil {
+		return fmt.Errorf("invalid config for github org '%s': %w", oc.Org, err)
+	}
+
+	oc.ignores, err = repos.CompilePatterns(oc.IgnoredRepos)
+	if err != nil {
+		return fmt.Errorf("invalid config for github org '%s': %w", oc.Org, err)
+	}
+
+	if err := oc.Policy.Validate(); err != nil {
+		return fmt.Errorf("invalid config for github org '%s': %w", oc.Org, err)
+	}
+
+	if oc.App != nil && oc.Credential != nil {
+		return fmt.Errorf("github org '%s' can't specify both 'app' and 'credential'", oc.Org)
+	}
+
+	if oc.App != nil {
+		if err := oc.App.Validate(); err != nil {
+			return fmt.Errorf("invalid config for github org '%s': %w", oc.Org, err)
+		}
+	}
+
+	if oc.Credential != nil {
+		if err := oc.Credential.Validate(); err != nil {
+			return fmt.Errorf("invalid config for github org '%s': %w", oc.Org, err)
+		}
+	}
+
+	for _, u := range []string{oc.BaseURL, oc.UploadURL} {
+		if _, err := url.Parse(u); err != nil {
+			return fmt.Errorf("invalid url for github org '%s': %w", oc.Org, err)
+		}
+	}
+
+	return nil
 }
 
 // GithubClient returns either a new instance of the Github client, or a previously
-// initialised client.
-func (oc *OrgConfig) GithubClient() *gh.Client {
+// initialised client. If a Github App is configured for the org, the client authenticates
+// as the app's installation, otherwise the Github token is used. If a base URL is configured,
+// the client targets that Github Enterprise Server instance rather than github.com.
+func (oc *OrgConfig) GithubClient() (*gh.Client, error) {
 	if oc.ghClient == nil {
-		ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: oc.token})
+		var ts oauth2.TokenSource
+		if oc.App != nil {
+			ts = newAppTokenSource(*oc.App, oc.Org, oc.BaseURL, oc.UploadURL)
+		} else {
+			ts = oauth2.StaticTokenSource(&oauth2.Token{AccessToken: oc.token})
+		}
+
 		tc := oauth2.NewClient(context.Background(), ts)
-		oc.ghClient = gh.NewClient(tc)
+
+		client, err := newClient(tc, oc.BaseURL, oc.UploadURL)
+		if err != nil {
+			return nil, fmt.Errorf("error creating github client for org '%s': %w", oc.Org, err)
+		}
+
+		oc.ghClient = client
 	}
 
-	return oc.ghClient
+	return oc.ghClient, nil
 }
 
 // SetGithubToken enables the setting of the Github token for the Github org.
 func (oc *OrgConfig) SetGithubToken(token string) {
 	oc.token = token
 }
+
+// SetRenderer sets the Renderer used to render the org's release bodies and commit messages.
+func (oc *OrgConfig) SetRenderer(renderer *markup.Renderer) {
+	oc.renderer = renderer
+}
+
+// Renderer returns the Renderer for the org, which uses the default link templates and HTML
+// policy if none was set.
+func (oc *OrgConfig) Renderer() *markup.Renderer {
+	if oc.renderer == nil {
+		oc.renderer = markup.NewRenderer((&markup.Config{}).Linker(sourceName), &markup.Policy{})
+	}
+
+	return oc.renderer
+}
+
+// SetMaxUnreleased sets the maximum number of unreleased commits listed for each repository.
+func (oc *OrgConfig) SetMaxUnreleased(n int) {
+	oc.maxUnreleased = &n
+}
+
+// MaxUnreleased returns the maximum number of unreleased commits listed for each repository,
+// which is repos.DefaultMaxUnreleased if none was set. Zero lists none of the commits.
+func (oc *OrgConfig) MaxUnreleased() int {
+	if oc.maxUnreleased == nil {
+		return repos.DefaultMaxUnreleased
+	}
+
+	return *oc.maxUnreleased
+}
+
+// SetArtifacts sets the artifacts declared in the config for repositories.
+func (oc *OrgConfig) SetArtifacts(artifacts repos.ArtifactConfigs) {
+	oc.artifacts = artifacts
+}
+
+// Artifacts returns the artifacts declared in the config for repositories.
+func (oc *OrgConfig) Artifacts() repos.ArtifactConfigs {
+	return oc.artifacts
+}
+
+// UsesDefaultToken reports whether the org authenticates with the default Github token, rather
+// than a Github App or a credential of its own.
+func (oc *OrgConfig) UsesDefaultToken() bool {
+	return oc.App == nil && oc.Credential == nil
+}
+
+// newClient returns a Github client that targets github.com, or the Github Enterprise Server
+// instance at baseURL if specified. The upload URL defaults to the base URL.
+func newClient(httpClient *http.Client, baseURL, uploadURL string) (*gh.Client, error) {
+	if baseURL == "" {
+		return gh.NewClient(httpClient), nil
+	}
+
+	if uploadURL == "" {
+		uploadURL = baseURL
+	}
+
+	return gh.NewEnterpriseClient(baseURL, uploadURL, httpClient)
+}
@@ -0,0 +1,83 @@
+package github
+
+import (
+	"fmt"
+	"slices"
+
+	gh "github.com/google/go-github/v54/github"
+	"github.com/jnsgruk/releasegen/internal/repos"
+)
+
+// The ways in which a kind of repository, such as a private or archived repository, can be
+// treated.
+const (
+	// Exclude omits the repositories from the report.
+	Exclude = "exclude"
+	// Include adds the repositories to the report like any other repository.
+	Include = "include"
+	// IncludeFlagged adds the repositories to the report, and sets the corresponding flag on
+	// the repository, such as 'private', so that consumers can tell them apart.
+	IncludeFlagged = "include-flagged"
+)
+
+// RepoPolicy configures whether private, archived, fork and template repositories are included
+// in the report. Empty values take the defaults in DefaultRepoPolicy.
+type RepoPolicy struct {
+	Private  string `mapstructure:"private"`
+	Archived string `mapstructure:"archived"`
+	Fork     string `mapstructure:"fork"`
+	Template string `mapstructure:"template"`
+}
+
+// DefaultRepoPolicy excludes private and archived repositories, and includes forks and templates.
+//
+//nolint:gochecknoglobals
+var DefaultRepoPolicy = RepoPolicy{Private: Exclude, Archived: Exclude, Fork: Include, Template: Include}
+
+// Validate checks that each of the policy's values is a known treatment.
+func (p RepoPolicy) Validate() error {
+	for _, v := range []string{p.Private, p.Archived, p.Fork, p.Template} {
+		if !slices.Contains([]string{"", Exclude, Include, IncludeFlagged}, v) {
+			return fmt.Errorf("unknown repo policy '%s'", v)
+		}
+	}
+
+	return nil
+}
+
+// apply decides whether the repository should be included in the report, according to the
+// policy. If it is, the details' flags are set for each of the repository's kinds that are
+// configured as IncludeFlagged.
+func (p RepoPolicy) apply(r *gh.Repository, details *repos.RepoDetails) bool {
+	rules := []struct {
+		is     bool
+		policy string
+		def    string
+		flag   *bool
+	}{
+		{r.GetPrivate(), p.Private, DefaultRepoPolicy.Private, &details.Private},
+		{r.GetArchived(), p.Archived, DefaultRepoPolicy.Archived, &details.Archived},
+		{r.GetFork(), p.Fork, DefaultRepoPolicy.Fork, &details.Fork},
+		{r.GetIsTemplate(), p.Template, DefaultRepoPolicy.Template, &details.Template},
+	}
+
+	for _, rule := range rules {
+		if !rule.is {
+			continue
+		}
+
+		policy := rule.policy
+		if policy == "" {
+			policy = rule.def
+		}
+
+		switch policy {
+		case Exclude:
+			return false
+		case IncludeFlagged:
+			*rule.flag = true
+		}
+	}
+
+	return true
+}
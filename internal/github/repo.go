@@ -5,46 +5,47 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
-	"regexp"
-	"strconv"
+	"slices"
+	"strings"
 
-	"github.com/gomarkdown/markdown"
 	gh "github.com/google/go-github/v54/github"
+	"github.com/jnsgruk/releasegen/internal/markup"
 	"github.com/jnsgruk/releasegen/internal/repos"
 )
 
-const githubReleasesPerRepo = 3
-
-var (
-	// prRegexp is used to find Github PR URLs in blocks of Markdown/HTML.
-	prRegexp = regexp.MustCompile(`(https://github.com/canonical/.+/pull/([0-9]+))`)
-	// prShortRegexp is used to find Github PR's mentioned as '#<PR>' (#34 for example).
-	prShortRegexp = regexp.MustCompile(`#([0-9]+)`)
-	// userRegexp is used to find Twitter/Github style mentions such as '@JoeBloggs' in Markdown/HTML.
-	userRegexp = regexp.MustCompile(`(\A|\s)@([\w-_]+)`)
-	// errFetchReadme is returned when a README could not be fetched or parsed.
-	errFetchReadme = errors.New("error getting README for repo")
+const (
+	githubReleasesPerRepo = 3
+	// githubReleaseHistory is the number of releases fetched to calculate release metrics, of
+	// which only the latest githubReleasesPerRepo are included in the report.
+	githubReleaseHistory = 100
+	// sourceName identifies Github as the source of problems recorded in the report.
+	sourceName = "github"
 )
 
+// errFetchReadme is returned when a README could not be fetched or parsed.
+var errFetchReadme = errors.New("error getting README for repo")
+
 // Repository represents a single Github Repository.
 type Repository struct {
 	Details       repos.RepoDetails
 	org           string // The Github Org that owns the repo.
-	team          string // The Github team, within the org, that has rights over the repo.
+	selector      string // The selector, such as a Github team within the org, that chose the repo.
 	client        *gh.Client
+	renderer      *markup.Renderer
+	maxUnreleased int                   // The maximum number of unreleased commits to list.
+	artifacts     *repos.ArtifactConfig // The artifacts declared in the config, if any.
 	defaultBranch string
+	// openPullRequests is the number of open pull requests counted for the whole org, or nil if
+	// they couldn't be, in which case they're counted for the repository alone.
+	openPullRequests *int
 }
 
 // Process populates the Repository with details of its releases, and commits.
 func (r *Repository) Process(ctx context.Context) error {
-	// Skip archived repositories.
-	if r.IsArchived(ctx) {
-		return nil
-	}
-
 	// Iterate over the releases in the Github repo and add them to our repository's details.
 	err := r.processReleases(ctx)
 	if err != nil {
+		r.Details.AddError(sourceName, repos.StageReleases, err)
 		return err
 	}
 
@@ -53,6 +54,7 @@ func (r *Repository) Process(ctx context.Context) error {
 		// Iterate over the tags in the Github repo and add them to our repository's details.
 		err := r.processTags(ctx)
 		if err != nil {
+			r.Details.AddError(sourceName, repos.StageTags, err)
 			return err
 		}
 	}
@@ -62,47 +64,87 @@ func (r *Repository) Process(ctx context.Context) error {
 		// If there are no releases, get the latest commit instead.
 		err := r.processCommits(ctx)
 		if err != nil {
+			r.Details.AddError(sourceName, repos.StageCommits, err)
 			return err
 		}
 	}
 
+	// Split the open issue count into issues and pull requests. The release data is complete at
+	// this point, so problems with metadata are only warnings.
+	err = r.processOpenPullRequests(ctx)
+	if err != nil {
+		r.Details.AddWarning(sourceName, repos.StageMetadata, err)
+	}
+
 	// Populate the repository's README from Github, parse any linked snaps, charms or CI actions.
+	// The release data is complete at this point, so README problems are only warnings.
 	err = r.parseReadme(ctx)
 	if err != nil {
+		r.Details.AddWarning(sourceName, repos.StageReadme, err)
 		return err
 	}
 
 	return nil
 }
 
-// IsArchived indicates whether or not the repository is marked as archived on Github.
-func (r *Repository) IsArchived(ctx context.Context) bool {
-	repoObject, _, err := r.client.Repositories.Get(ctx, r.org, r.Details.Name)
-	if err != nil {
-		return false
-	}
-
-	return repoObject.GetArchived()
-}
-
 // parseReadme is a helper function to fetch the README from a Github repository and return
 // its contents as a string.
 func (r *Repository) parseReadme(ctx context.Context) error {
-	githubReadme, _, err := r.client.Repositories.GetReadme(ctx, r.org, r.Details.Name, nil)
+	readme := &repos.Readme{}
+
+	githubReadme, res, err := r.client.Repositories.GetReadme(ctx, r.org, r.Details.Name, nil)
 	if err != nil {
-		return errFetchReadme
+		// Not every repository has a README, but artifacts may still be declared in the config.
+		// Artifacts declared in the config don't depend on the README, so are populated even if it
+		// couldn't be fetched.
+		readme.PopulateArtifacts(ctx, &r.Details, r.artifacts)
+
+		if errors.Is(checkResponse(res, err), ErrNotFound) {
+			return nil
+		}
+
+		return fmt.Errorf("%w: %w", errFetchReadme, checkResponse(res, err))
 	}
 
-	content, err := githubReadme.GetContent()
+	readme.Body, err = githubReadme.GetContent()
 	if err != nil {
+		readme.PopulateArtifacts(ctx, &r.Details, r.artifacts)
 		return errFetchReadme
 	}
 
 	// Parse contents of README to identify associated Github Workflows, snaps, charms.
-	readme := &repos.Readme{Body: content}
 	r.Details.CiActions = readme.GithubActions()
-	r.Details.Snap = readme.LinkedSnap(ctx)
-	r.Details.Charm = readme.LinkedCharm(ctx)
+	readme.PopulateArtifacts(ctx, &r.Details, r.artifacts)
+
+	return nil
+}
+
+// processOpenPullRequests counts the repository's open pull requests, unless they were counted
+// for the whole org. Github includes pull requests in a repository's open issue count, so
+// they're subtracted from r.Details.OpenIssues.
+func (r *Repository) processOpenPullRequests(ctx context.Context) error {
+	if r.openPullRequests != nil {
+		r.Details.OpenPullRequests = *r.openPullRequests
+		r.Details.OpenIssues = max(r.Details.OpenIssues-r.Details.OpenPullRequests, 0)
+
+		return nil
+	}
+
+	// With one pull request per page, the number of the last page is the number of pull requests.
+	opts := &gh.PullRequestListOptions{State: "open", ListOptions: gh.ListOptions{PerPage: 1}}
+
+	pulls, res, err := r.client.PullRequests.List(ctx, r.org, r.Details.Name, opts)
+	if err != nil {
+		return fmt.Errorf("error listing pull requests for repo: %w", checkResponse(res, err))
+	}
+
+	r.Details.OpenPullRequests = res.LastPage
+	if res.LastPage == 0 {
+		// There is no last page if all of the pull requests fit on the first.
+		r.Details.OpenPullRequests = len(pulls)
+	}
+
+	r.Details.OpenIssues = max(r.Details.OpenIssues-r.Details.OpenPullRequests, 0)
 
 	return nil
 }
@@ -110,22 +152,31 @@ func (r *Repository) parseReadme(ctx context.Context) error {
 // processReleases fetches a repository's releases from Github, then populates r.Details.Releases
 // with the information in the relevant format for releasegen.
 func (r *Repository) processReleases(ctx context.Context) error {
-	opts := &gh.ListOptions{PerPage: githubReleasesPerRepo}
+	opts := &gh.ListOptions{PerPage: githubReleaseHistory}
 
-	releases, _, err := r.client.Repositories.ListReleases(ctx, r.org, r.Details.Name, opts)
+	releases, res, err := r.client.Repositories.ListReleases(ctx, r.org, r.Details.Name, opts)
 	if err != nil {
-		return errors.New("error listing releases for repo")
+		return fmt.Errorf("error listing releases for repo: %w", checkResponse(res, err))
 	}
 
+	r.Details.HistoryTruncated = res.NextPage != 0
+
 	for _, rel := range releases {
+		r.Details.ReleaseHistory = append(r.Details.ReleaseHistory, unixTime(rel.GetCreatedAt()))
+	}
+
+	for _, rel := range releases[:min(len(releases), githubReleasesPerRepo)] {
 		r.Details.Releases = append(r.Details.Releases, &repos.Release{
-			ID:         rel.GetID(),
-			Version:    rel.GetTagName(),
-			Timestamp:  rel.CreatedAt.Time.Unix(),
-			Title:      rel.GetName(),
-			Body:       renderReleaseBody(rel.GetBody(), r),
-			URL:        rel.GetHTMLURL(),
-			CompareURL: fmt.Sprintf("%s/compare/%s...%s", r.Details.URL, rel.GetTagName(), r.defaultBranch),
+			ID:           rel.GetID(),
+			Version:      rel.GetTagName(),
+			Timestamp:    rel.CreatedAt.Time.Unix(),
+			Title:        rel.GetName(),
+			Body:         r.renderer.HTML(rel.GetBody(), r.ref()),
+			BodyMarkdown: r.renderer.Markdown(rel.GetBody()),
+			Summary:      markup.Summary(rel.GetBody()),
+			Changelog:    repos.ParseChangelog(rel.GetBody(), r.Details.URL),
+			URL:          rel.GetHTMLURL(),
+			CompareURL:   fmt.Sprintf("%s/compare/%s...%s", r.Details.URL, rel.GetTagName(), r.defaultBranch),
 		})
 	}
 
@@ -145,25 +196,32 @@ func (r *Repository) processReleases(ctx context.Context) error {
 func (r *Repository) processTags(ctx context.Context) error {
 	opts := &gh.ListOptions{PerPage: githubReleasesPerRepo}
 
-	tags, _, err := r.client.Repositories.ListTags(ctx, r.org, r.Details.Name, opts)
+	tags, res, err := r.client.Repositories.ListTags(ctx, r.org, r.Details.Name, opts)
 	if err != nil {
-		return errors.New("error listing tags for repo")
+		return fmt.Errorf("error listing tags for repo: %w", checkResponse(res, err))
 	}
 
+	// Only the latest tags are fetched, as each needs its commit to be fetched for its timestamp,
+	// so older tags are missing from the history.
+	r.Details.HistoryTruncated = res.NextPage != 0
+
 	for _, tag := range tags {
 		// Without fetching the commit separately, the timestamp/author information isn't populated
-		commit, _, err := r.client.Repositories.GetCommit(ctx, r.org, r.Details.Name, tag.GetCommit().GetSHA(), nil)
+		commit, res, err := r.client.Repositories.GetCommit(ctx, r.org, r.Details.Name, tag.GetCommit().GetSHA(), nil)
 		if err != nil {
-			return errors.New("error getting commit info for tag")
+			return fmt.Errorf("error getting commit info for tag: %w", checkResponse(res, err))
 		}
 
+		r.Details.ReleaseHistory = append(r.Details.ReleaseHistory, unixTime(commit.GetCommit().GetAuthor().GetDate()))
 		r.Details.Tags = append(r.Details.Tags, &repos.Tag{
-			Name:       tag.GetName(),
-			Sha:        tag.GetCommit().GetSHA(),
-			Body:       renderReleaseBody(commit.GetCommit().GetMessage(), r),
-			Timestamp:  commit.GetCommit().GetAuthor().GetDate().Time.Unix(),
-			URL:        fmt.Sprintf("%s/releases/tag/%s", r.Details.URL, url.PathEscape(tag.GetName())),
-			CompareURL: fmt.Sprintf("%s/compare/%s...%s", r.Details.URL, r.defaultBranch, url.PathEscape(tag.GetName())),
+			Name:         tag.GetName(),
+			Sha:          tag.GetCommit().GetSHA(),
+			Body:         r.renderer.HTML(commit.GetCommit().GetMessage(), r.ref()),
+			BodyMarkdown: r.renderer.Markdown(commit.GetCommit().GetMessage()),
+			Summary:      markup.Summary(commit.GetCommit().GetMessage()),
+			Timestamp:    commit.GetCommit().GetAuthor().GetDate().Time.Unix(),
+			URL:          fmt.Sprintf("%s/releases/tag/%s", r.Details.URL, url.PathEscape(tag.GetName())),
+			CompareURL:   fmt.Sprintf("%s/compare/%s...%s", r.Details.URL, r.defaultBranch, url.PathEscape(tag.GetName())),
 		})
 	}
 
@@ -179,18 +237,64 @@ func (r *Repository) processTags(ctx context.Context) error {
 }
 
 // processCommitsSince calculates the number of commits that have occurred on the default
-// branch of the repository since the last release, and populates the information in r.Details.
+// branch of the repository since the last release, and populates the information in r.Details,
+// along with a list of the most recent of those commits.
 func (r *Repository) processCommitsSince(ctx context.Context, comparator string) error {
-	opts := &gh.ListOptions{PerPage: githubReleasesPerRepo}
+	// The comparison is still needed for the number of commits if none are listed, so at least one
+	// commit is fetched.
+	opts := &gh.ListOptions{PerPage: max(r.maxUnreleased, 1)}
+
 	// Add the commit delta between last release and default branch.
-	comparison, _, err := r.client.Repositories.CompareCommits(
+	comparison, res, err := r.client.Repositories.CompareCommits(
 		ctx, r.org, r.Details.Name, comparator, r.defaultBranch, opts,
 	)
 	if err != nil {
-		return errors.New("error getting commit comparison for release")
+		return fmt.Errorf("error getting commit comparison for release: %w", checkResponse(res, err))
+	}
+
+	r.Details.NewCommits = comparison.GetTotalCommits()
+	commits := comparison.Commits
+
+	// The comparison lists the oldest commits first.
+	if len(commits) > 0 {
+		r.Details.OldestUnreleased = unixTime(commits[0].GetCommit().GetAuthor().GetDate())
+	}
+
+	// The newest commits are on the last pages.
+	if r.maxUnreleased > 0 && r.Details.NewCommits > len(commits) {
+		commits = []*gh.RepositoryCommit{}
+		lastPage := (r.Details.NewCommits + opts.PerPage - 1) / opts.PerPage
+
+		for page := max(lastPage-1, 1); page <= lastPage; page++ {
+			opts.Page = page
+
+			comparison, res, err := r.client.Repositories.CompareCommits(
+				ctx, r.org, r.Details.Name, comparator, r.defaultBranch, opts,
+			)
+			if err != nil {
+				return fmt.Errorf("error getting commit comparison for release: %w", checkResponse(res, err))
+			}
+
+			commits = append(commits, comparison.Commits...)
+		}
 	}
 
-	r.Details.NewCommits = *comparison.TotalCommits
+	// List the newest commits first.
+	commits = commits[max(len(commits)-r.maxUnreleased, 0):]
+	r.Details.Unreleased = []*repos.Unreleased{}
+
+	for _, commit := range slices.Backward(commits) {
+		subject, _, _ := strings.Cut(commit.GetCommit().GetMessage(), "\n")
+
+		r.Details.Unreleased = append(r.Details.Unreleased, &repos.Unreleased{
+			Sha:         commit.GetSHA(),
+			Subject:     subject,
+			Author:      commit.GetCommit().GetAuthor().GetName(),
+			Timestamp:   unixTime(commit.GetCommit().GetAuthor().GetDate()),
+			PullRequest: repos.MergedPullRequest(subject),
+			URL:         commit.GetHTMLURL(),
+		})
+	}
 
 	return nil
 }
@@ -201,50 +305,35 @@ func (r *Repository) processCommits(ctx context.Context) error {
 	opts := &gh.CommitsListOptions{ListOptions: gh.ListOptions{PerPage: githubReleasesPerRepo}}
 
 	// If there are no releases, get the latest commit instead.
-	commits, _, err := r.client.Repositories.ListCommits(ctx, r.org, r.Details.Name, opts)
+	commits, res, err := r.client.Repositories.ListCommits(ctx, r.org, r.Details.Name, opts)
 	if err != nil {
-		return errors.New("error listing commits for repository")
+		return fmt.Errorf("error listing commits for repository: %w", checkResponse(res, err))
 	}
 
 	// Iterate over the commits and append them to r.Details.Commits
 	for _, commit := range commits {
 		ts := commit.GetCommit().GetAuthor().GetDate()
 		r.Details.Commits = append(r.Details.Commits, &repos.Commit{
-			Sha:       commit.GetSHA(),
-			Author:    commit.GetCommit().GetAuthor().GetName(),
-			Timestamp: ts.GetTime().Unix(),
-			Message:   renderReleaseBody(commit.GetCommit().GetMessage(), r),
-			URL:       commit.GetHTMLURL(),
+			Sha:          commit.GetSHA(),
+			Author:       commit.GetCommit().GetAuthor().GetName(),
+			Timestamp:    ts.GetTime().Unix(),
+			Message:      r.renderer.HTML(commit.GetCommit().GetMessage(), r.ref()),
+			BodyMarkdown: r.renderer.Markdown(commit.GetCommit().GetMessage()),
+			Summary:      markup.Summary(commit.GetCommit().GetMessage()),
+			URL:          commit.GetHTMLURL(),
 		})
 	}
 
 	return nil
 }
 
-// renderReleaseBody transforms a Markdown string from a Github Release into HTML.
-func renderReleaseBody(body string, repo *Repository) string {
-	// Preprocess any Pull Request links in the Markdown body.
-	body = prRegexp.ReplaceAllString(body, `<a target="_blank" href="${1}">#${2}</a>`)
-	// Preprocess any user mentions in the Markdown body.
-	body = userRegexp.ReplaceAllString(body, `${1}<a target="_blank" href="https://github.com/${2}">@${2}</a>`)
-
-	// Preprocess any mentions of PR's using the shorthand notation #<PR>
-	body = prShortRegexp.ReplaceAllStringFunc(body, func(s string) string {
-		// Grab the number of the PR; if we fail to parse the number just return the original str.
-		num, err := strconv.Atoi(s[1:])
-		if err != nil {
-			return s
-		}
-
-		// Construct the possible URL of the PR.
-		url := fmt.Sprintf("https://github.com/%s/%s/pull/%d", repo.org, repo.Details.Name, num)
-
-		return fmt.Sprintf(`<a target="_blank" href="%s">#%d</a>`, url, num)
-	})
-
-	// Render the Markdown to HTML.
-	md := []byte(body)
-	normalised := markdown.NormalizeNewlines(md)
+// ref identifies the repository to the renderer, so that references such as '#34' are relative
+// to the repository, on the Github instance it's from.
+func (r *Repository) ref() markup.Ref {
+	base := "https://github.com"
+	if u, err := url.Parse(r.Details.URL); err == nil && u.Host != "" {
+		base = fmt.Sprintf("%s://%s", u.Scheme, u.Host)
+	}
 
-	return string(markdown.ToHTML(normalised, nil, nil))
+	return markup.Ref{Base: base, Owner: r.Details.Owner, Repo: r.Details.Name}
 }
@@ -2,89 +2,329 @@ package github
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"path"
 	"slices"
+	"strings"
 
 	gh "github.com/google/go-github/v54/github"
 	"github.com/jnsgruk/releasegen/internal/repos"
 )
 
-const githubPerPage = 1000
+const (
+	githubPerPage = 1000
+	// githubSearchPerPage is the most results the search API returns per page, and
+	// githubSearchLimit the most results it returns for a query.
+	githubSearchPerPage = 100
+	githubSearchLimit   = 1000
+)
+
+// errSearchLimit is returned when a search has more results than the search API returns.
+var errSearchLimit = errors.New("too many results for the github search api")
+
+// selectedRepo is a repository chosen by one of the selectors in an OrgConfig. If the
+// repository was listed explicitly but couldn't be fetched, err is set and repo only has a name.
+type selectedRepo struct {
+	repo     *gh.Repository
+	selector string
+	err      error
+}
+
+// FetchOrgRepos creates a slice of RepoDetails types representing the repos selected
+// by the teams, topics, name patterns and repo lists configured for the Github org. Each repo
+// is claimed by the team in the index, and is only processed if it's not already in the index.
+func FetchOrgRepos(org *OrgConfig, index *repos.Index, team string) ([]*repos.RepoDetails, error) {
+	orgRepos := []*repos.RepoDetails{}
+	ctx := context.Background()
+
+	client, err := org.GithubClient()
+	if err != nil {
+		return nil, err
+	}
 
-// FetchOrgRepos creates a slice of RepoDetails types representing the repos
-// owned by the specified teams in the Github org.
-func FetchOrgRepos(org OrgConfig) ([]repos.RepoDetails, error) {
-	orgRepos := []repos.RepoDetails{}
+	selected, err := org.selectRepos(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	// Open pull requests are counted for the whole org at once, rather than for each repository.
+	openPulls, err := org.countOpenPullRequests(ctx, client)
+	if err != nil {
+		log.Printf("error counting open pull requests for github org '%s', counting per repo: %s", org.Org, err.Error())
+	}
+
+	// Iterate over repositories, populating release info for each.
+	for _, s := range selected {
+		r := s.repo
+
+		// Prefer the owner's login as reported by Github, whose case may differ from the config.
+		owner := r.GetOwner().GetLogin()
+		if owner == "" {
+			owner = org.Org
+		}
+
+		id := repos.ID(sourceName, owner, r.GetName())
+
+		repo := &Repository{
+			Details: repos.RepoDetails{
+				ID:            id,
+				Source:        sourceName,
+				Owner:         owner,
+				Name:          r.GetName(),
+				URL:           r.GetHTMLURL(),
+				Description:   r.GetDescription(),
+				Topics:        r.Topics,
+				Language:      r.GetLanguage(),
+				License:       r.GetLicense().GetSPDXID(),
+				Stars:         r.GetStargazersCount(),
+				OpenIssues:    r.GetOpenIssuesCount(),
+				DefaultBranch: r.GetDefaultBranch(),
+				Homepage:      r.GetHomepage(),
+				PushedAt:      unixTime(r.GetPushedAt()),
+			},
+			org:           org.Org,
+			selector:      s.selector,
+			client:        client,
+			renderer:      org.Renderer(),
+			maxUnreleased: org.MaxUnreleased(),
+			artifacts:     org.Artifacts().For(id),
+			defaultBranch: r.GetDefaultBranch(),
+		}
+
+		if org.ignores.MatchesRepo(repo.Details.ID, repo.Details.Name) {
+			continue
+		}
+
+		if openPulls != nil {
+			count := openPulls[strings.ToLower(r.GetName())]
+			repo.openPullRequests = &count
+		}
+
+		// A repository in the explicit list that couldn't be fetched is reported with its error,
+		// rather than failing the whole org.
+		if s.err != nil {
+			repo.Details.AddError(sourceName, repos.StageRepos, s.err)
+			orgRepos = append(orgRepos, index.Fetch(id, team, func() *repos.RepoDetails { return &repo.Details }))
+
+			continue
+		}
+
+		// Check if the repository is excluded by the policy for private, archived, fork or
+		// template repositories.
+		if !org.Policy.apply(r, &repo.Details) {
+			continue
+		}
+
+		details := index.Fetch(repo.Details.ID, team, func() *repos.RepoDetails {
+			log.Printf("processing github repo: %s/%s/%s\n", repo.org, repo.selector, repo.Details.Name)
+
+			err := repo.Process(ctx)
+			if err != nil {
+				log.Printf("error populating repo '%s' from github: %s", repo.Details.Name, err.Error())
+			}
+
+			return &repo.Details
+		})
+
+		// Add the repos that have at least one commit, or that couldn't be fully processed so
+		// that the errors are visible in the report.
+		if (len(details.Releases)+len(details.Tags)+len(details.Commits)) > 0 || len(details.Errors) > 0 {
+			orgRepos = append(orgRepos, details)
+		}
+	}
+
+	return orgRepos, nil
+}
+
+// selectRepos lists the repositories chosen by each of the org's selectors, in the order teams,
+// explicit repos, then repos listed from the whole org. Each repository is only returned once.
+func (oc *OrgConfig) selectRepos(ctx context.Context, client *gh.Client) ([]*selectedRepo, error) {
+	selected := []*selectedRepo{}
+	seen := map[string]bool{}
+
+	add := func(r *gh.Repository, selector string) {
+		if !seen[r.GetName()] {
+			seen[r.GetName()] = true
+			selected = append(selected, &selectedRepo{repo: r, selector: selector})
+		}
+	}
 
 	// Iterate over the Github Teams, listing repos for each.
-	for _, team := range org.Teams {
-		// Process the Github orgs repositories into structs with the info we need.
-		teamRepos, err := getTeamRepos(org, team, &orgRepos)
+	for _, team := range oc.Teams {
+		teamRepos, err := listTeamRepos(ctx, client, oc.Org, team)
 		if err != nil {
 			return nil, err
 		}
 
-		// Iterate over ghRepos and add the unarchived ones that have at least one commit.
+		log.Printf("found %d repositories for github team: %s", len(teamRepos), team)
+
 		for _, r := range teamRepos {
-			if (len(r.Details.Releases) + len(r.Details.Tags) + len(r.Details.Commits)) > 0 {
-				orgRepos = append(orgRepos, r.Details)
+			add(r, team)
+		}
+	}
+
+	for _, name := range oc.Repos {
+		r, res, err := client.Repositories.Get(ctx, oc.Org, name)
+		if err != nil {
+			if !seen[name] {
+				seen[name] = true
+				selected = append(selected, &selectedRepo{
+					repo:     &gh.Repository{Name: gh.String(name)},
+					selector: "repos",
+					err:      fmt.Errorf("error getting github repository '%s/%s': %w", oc.Org, name, checkResponse(res, err)),
+				})
 			}
+
+			continue
 		}
+
+		add(r, "repos")
 	}
 
-	return orgRepos, nil
+	// Only list every repository in the org if a selector needs it.
+	if !oc.AllRepos && len(oc.Topics) == 0 && len(oc.NamePatterns) == 0 {
+		return selected, nil
+	}
+
+	ownerRepos, err := oc.listOwnerRepos(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range ownerRepos {
+		switch {
+		case oc.AllRepos:
+			add(r, "all")
+		case hasAnyTopic(r, oc.Topics):
+			add(r, "topics")
+		case oc.namePatterns.MatchesAny(r.GetName()):
+			add(r, "name-patterns")
+		}
+	}
+
+	return selected, nil
 }
 
-// getTeamRepos fetches a team's repos from the Github client and processes them into
-// the format releasegen needs.
-func getTeamRepos(org OrgConfig, team string, orgRepos *[]repos.RepoDetails) ([]*Repository, error) {
-	ghRepos := []*Repository{}
-	ctx := context.Background()
+// countOpenPullRequests counts the open pull requests in each of the owner's repositories, keyed
+// by the lower case repository name, using a single search query. It takes one request per 100
+// open pull requests, rather than one per repository. If the owner has more open pull requests
+// than the search API returns, errSearchLimit is returned.
+func (oc *OrgConfig) countOpenPullRequests(ctx context.Context, client *gh.Client) (map[string]int, error) {
+	qualifier := "org"
+	if oc.User {
+		qualifier = "user"
+	}
+
+	query := fmt.Sprintf("is:pr is:open %s:%s", qualifier, oc.Org)
+	opts := &gh.SearchOptions{ListOptions: gh.ListOptions{PerPage: githubSearchPerPage}}
+	counts := map[string]int{}
+
+	for {
+		result, resp, err := client.Search.Issues(ctx, query, opts)
+		if err != nil {
+			return nil, fmt.Errorf("error searching open pull requests: %w", checkResponse(resp, err))
+		}
+
+		if result.GetIncompleteResults() || result.GetTotal() > githubSearchLimit {
+			return nil, errSearchLimit
+		}
+
+		for _, issue := range result.Issues {
+			// The repository URL is that of the repository in the API, which ends with its name.
+			name := path.Base(issue.GetRepositoryURL())
+			counts[strings.ToLower(name)]++
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opts.Page = resp.NextPage
+	}
+
+	return counts, nil
+}
+
+// listTeamRepos lists the Github repositories that the team has access to.
+func listTeamRepos(ctx context.Context, client *gh.Client, org, team string) ([]*gh.Repository, error) {
 	opts := &gh.ListOptions{PerPage: githubPerPage}
-	allTeamRepos:= []*gh.Repository{}
+	allTeamRepos := []*gh.Repository{}
 
-	// Lists the Github repositories that the 'ghTeam' has access to.
 	for {
-		teamRepos, resp, err := org.GithubClient().Teams.ListTeamReposBySlug(ctx, org.Org, team, opts)
+		teamRepos, resp, err := client.Teams.ListTeamReposBySlug(ctx, org, team, opts)
 		if err != nil {
-			return nil, fmt.Errorf("error listing repositories for github org: %s", org.Org)
+			return nil, fmt.Errorf("error listing repositories for github org '%s': %w", org, checkResponse(resp, err))
 		}
+
 		allTeamRepos = append(allTeamRepos, teamRepos...)
+
 		if resp.NextPage == 0 {
 			break
 		}
+
 		opts.Page = resp.NextPage
 	}
-	log.Printf("found %d repositories for github team: %s", len(allTeamRepos), team)
 
-	// Iterate over repositories, populating release info for each.
-	for _, tRepo := range allTeamRepos {
-		r := tRepo
-		// Check if the name of the repository is in the ignore list or private, or already processed.
-		if slices.Contains(org.IgnoredRepos, *r.Name) || *r.Private || repos.RepoInSlice(*orgRepos, *r.Name) {
-			continue
+	return allTeamRepos, nil
+}
+
+// listOwnerRepos lists every repository owned by the org, or by the user if the OrgConfig
+// refers to a user account.
+func (oc *OrgConfig) listOwnerRepos(ctx context.Context, client *gh.Client) ([]*gh.Repository, error) {
+	listOpts := gh.ListOptions{PerPage: githubPerPage}
+	allRepos := []*gh.Repository{}
+
+	for {
+		var (
+			page []*gh.Repository
+			resp *gh.Response
+			err  error
+		)
+
+		if oc.User {
+			opts := &gh.RepositoryListOptions{Type: "owner", ListOptions: listOpts}
+			page, resp, err = client.Repositories.List(ctx, oc.Org, opts)
+		} else {
+			opts := &gh.RepositoryListByOrgOptions{Type: "all", ListOptions: listOpts}
+			page, resp, err = client.Repositories.ListByOrg(ctx, oc.Org, opts)
 		}
 
-		repo := &Repository{
-			Details: repos.RepoDetails{
-				Name: *r.Name,
-				URL:  *r.HTMLURL,
-			},
-			org:           org.Org,
-			team:          team,
-			client:        org.GithubClient(),
-			defaultBranch: *r.DefaultBranch,
+		if err != nil {
+			return nil, fmt.Errorf("error listing repositories for github owner '%s': %w", oc.Org, checkResponse(resp, err))
 		}
-		ghRepos = append(ghRepos, repo)
 
-		log.Printf("processing github repo: %s/%s/%s\n", repo.org, repo.team, repo.Details.Name)
+		allRepos = append(allRepos, page...)
 
-		err := repo.Process(ctx)
-		if err != nil {
-			log.Printf("error populating repo '%s' from github: %s", repo.Details.Name, err.Error())
+		if resp.NextPage == 0 {
+			break
+		}
+
+		listOpts.Page = resp.NextPage
+	}
+
+	log.Printf("found %d repositories for github owner: %s", len(allRepos), oc.Org)
+
+	return allRepos, nil
+}
+
+// unixTime returns the timestamp as a Unix time, or zero if the timestamp isn't set.
+func unixTime(ts gh.Timestamp) int64 {
+	if ts.IsZero() {
+		return 0
+	}
+
+	return ts.Unix()
+}
+
+// hasAnyTopic reports whether the repository is tagged with any of the topics.
+func hasAnyTopic(r *gh.Repository, topics []string) bool {
+	for _, topic := range topics {
+		if slices.Contains(r.Topics, topic) {
+			return true
 		}
 	}
 
-	return ghRepos, nil
+	return false
 }
@@ -7,50 +7,195 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/jnsgruk/releasegen/internal/credentials"
+	"github.com/jnsgruk/releasegen/internal/markup"
+	"github.com/jnsgruk/releasegen/internal/repos"
 	"github.com/tidwall/gjson"
 )
 
-const launchpadTimeout = 5 * time.Second
+const (
+	launchpadTimeout = 5 * time.Second
+	// apiHost is the host of the Launchpad API, the only host that accepts OAuth credentials.
+	apiHost = "api.launchpad.net"
+	// sourceName identifies Launchpad as the source of problems recorded in the report.
+	sourceName = "launchpad"
+)
+
+// spdxLicenses maps the names of licences in Launchpad to their SPDX identifiers.
+//
+//nolint:gochecknoglobals
+var spdxLicenses = map[string]string{
+	"Academic Free License":                 "AFL-3.0",
+	"Affero GPL":                            "AGPL-3.0-only",
+	"Apache Licence":                        "Apache-2.0",
+	"Artistic License 1.0":                  "Artistic-1.0",
+	"Artistic License 2.0":                  "Artistic-2.0",
+	"Simple BSD":                            "BSD-2-Clause",
+	"Modified BSD Licence":                  "BSD-3-Clause",
+	"Creative Commons - No Rights Reserved": "CC0-1.0",
+	"Eclipse Public Licence":                "EPL-1.0",
+	"GNU GPL v2":                            "GPL-2.0-only",
+	"GNU GPL v3":                            "GPL-3.0-only",
+	"GNU LGPL v2.1":                         "LGPL-2.1-only",
+	"GNU LGPL v3":                           "LGPL-3.0-only",
+	"MIT / X / Expat Licence":               "MIT",
+	"Mozilla Public Licence":                "MPL-1.1",
+	"Mozilla Public Licence 2.0":            "MPL-2.0",
+	"Python Licence":                        "Python-2.0",
+	"Zope Public Licence":                   "ZPL-2.1",
+}
 
 // Config contains fields used in releasegen's config.yaml file to configure
 // its behaviour when generating reports about Launchpad repositories.
 type Config struct {
-	ProjectGroups []string `mapstructure:"project-groups"`
-	IgnoredRepos  []string `mapstructure:"ignores"`
+	ProjectGroups []string         `mapstructure:"project-groups"`
+	IgnoredRepos  []string         `mapstructure:"ignores"`
+	Credential    *credentials.Ref `mapstructure:"credential"`
+
+	oauth    *OAuthCredentials
+	renderer *markup.Renderer
+	// ignores are the compiled IgnoredRepos, set by Validate.
+	ignores repos.Patterns
+
+	maxUnreleased *int
+	artifacts     repos.ArtifactConfigs
 }
 
-// enumerateProjectGroup lists the projects that are part of the specified project group.
-func enumerateProjectGroup(ctx context.Context, projectGroup string) ([]string, error) {
-	url := fmt.Sprintf("https://api.launchpad.net/devel/%s/projects", projectGroup)
+// Validate checks that the ignores and credential of the config are well formed, and compiles
+// the ignores.
+func (c *Config) Validate() error {
+	var err error
 
-	client := http.Client{Timeout: launchpadTimeout}
+	c.ignores, err = repos.CompilePatterns(c.IgnoredRepos)
+	if err != nil {
+		return fmt.Errorf("invalid launchpad ignores: %w", err)
+	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if c.Credential != nil {
+		if err := c.Credential.Validate(); err != nil {
+			return fmt.Errorf("invalid launchpad credential: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Ignores returns the compiled patterns of the repositories to ignore.
+func (c *Config) Ignores() repos.Patterns {
+	return c.ignores
+}
+
+// SetOAuthCredentials sets the OAuth credentials used to authenticate with the Launchpad API,
+// from their serialised form.
+func (c *Config) SetOAuthCredentials(s string) error {
+	oauth, err := ParseOAuthCredentials(s)
 	if err != nil {
-		return nil, fmt.Errorf("error enumerating project group '%s': %w", projectGroup, err)
+		return err
 	}
 
-	res, getErr := client.Do(req)
-	if getErr != nil {
+	c.oauth = oauth
+
+	return nil
+}
+
+// SetRenderer sets the Renderer used to render the tag messages of the project groups.
+func (c *Config) SetRenderer(renderer *markup.Renderer) {
+	c.renderer = renderer
+}
+
+// Renderer returns the Renderer for the project groups, which uses the default link templates
+// and HTML policy if none was set.
+func (c *Config) Renderer() *markup.Renderer {
+	if c.renderer == nil {
+		return markup.NewRenderer((&markup.Config{}).Linker(sourceName), &markup.Policy{})
+	}
+
+	return c.renderer
+}
+
+// SetMaxUnreleased sets the maximum number of unreleased commits listed for each repository.
+func (c *Config) SetMaxUnreleased(n int) {
+	c.maxUnreleased = &n
+}
+
+// MaxUnreleased returns the maximum number of unreleased commits listed for each repository,
+// which is repos.DefaultMaxUnreleased if none was set. Zero lists none of the commits.
+func (c *Config) MaxUnreleased() int {
+	if c.maxUnreleased == nil {
+		return repos.DefaultMaxUnreleased
+	}
+
+	return *c.maxUnreleased
+}
+
+// SetArtifacts sets the artifacts declared in the config for projects.
+func (c *Config) SetArtifacts(artifacts repos.ArtifactConfigs) {
+	c.artifacts = artifacts
+}
+
+// Artifacts returns the artifacts declared in the config for projects.
+func (c *Config) Artifacts() repos.ArtifactConfigs {
+	return c.artifacts
+}
+
+// get makes every request to Launchpad. Requests to api.launchpad.net are signed with the OAuth
+// credentials if there are any. git.launchpad.net, whose pages are scraped for tags and commits,
+// doesn't accept OAuth credentials, so requests to it are always anonymous.
+func get(ctx context.Context, rawURL string, oauth *OAuthCredentials) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if oauth != nil && req.URL.Host == apiHost {
+		oauth.sign(req)
+	}
+
+	client := &http.Client{Timeout: launchpadTimeout}
+
+	return client.Do(req)
+}
+
+// enumerateProjectGroup lists the projects that are part of the specified project group.
+func enumerateProjectGroup(ctx context.Context, projectGroup string, config Config) ([]*projectInfo, error) {
+	url := fmt.Sprintf("https://%s/devel/%s/projects", apiHost, projectGroup)
+
+	res, err := get(ctx, url, config.oauth)
+	if err != nil {
 		return nil, fmt.Errorf("error enumerating project group '%s': %w", projectGroup, err)
 	}
 	defer res.Body.Close()
 
+	if err := checkStatus(res); err != nil {
+		return nil, fmt.Errorf("error enumerating project group '%s': %w", projectGroup, err)
+	}
+
 	body, readErr := io.ReadAll(res.Body)
 	if readErr != nil {
-		return nil, fmt.Errorf("error enumerating project group '%s': %w", projectGroup, err)
+		return nil, fmt.Errorf("error enumerating project group '%s': %w", projectGroup, readErr)
 	}
 
 	// Parse the result as JSON, grab the "entries" key.
 	result := gjson.Get(string(body), "entries")
-	projects := []string{}
+	projects := []*projectInfo{}
 
 	// Iterate over the entries.
 	result.ForEach(func(key, value gjson.Result) bool {
 		// If the entry doesn't use Git as it's VCS, move on.
 		if vcs := gjson.Get(value.Raw, "vcs").String(); vcs == "Git" {
-			// If the project does use Git, add the project name to the output array.
-			projects = append(projects, gjson.Get(value.Raw, "name").String())
+			// If the project does use Git, add the project to the output array.
+			info := &projectInfo{
+				Name:     gjson.Get(value.Raw, "name").String(),
+				Summary:  gjson.Get(value.Raw, "summary").String(),
+				Homepage: gjson.Get(value.Raw, "homepage_url").String(),
+				Language: gjson.Get(value.Raw, "programming_language").String(),
+			}
+
+			for _, l := range gjson.Get(value.Raw, "licenses").Array() {
+				info.Licenses = append(info.Licenses, l.String())
+			}
+
+			projects = append(projects, info)
 		}
 
 		return true
@@ -58,3 +203,38 @@ func enumerateProjectGroup(ctx context.Context, projectGroup string) ([]string,
 
 	return projects, nil
 }
+
+// CheckProjectGroup verifies that a Launchpad project group resolves, and returns the names of
+// the Git based projects it contains.
+func CheckProjectGroup(ctx context.Context, projectGroup string, config Config) ([]string, error) {
+	projects, err := enumerateProjectGroup(ctx, projectGroup, config)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, p := range projects {
+		names = append(names, p.Name)
+	}
+
+	return names, nil
+}
+
+// projectInfo holds the details of a project that are returned when enumerating a project group.
+type projectInfo struct {
+	Name     string
+	Summary  string
+	Homepage string
+	Language string
+	Licenses []string
+}
+
+// spdxLicense returns the SPDX identifier of the project's licence, if it has exactly one
+// licence that has an SPDX equivalent.
+func (p *projectInfo) spdxLicense() string {
+	if len(p.Licenses) != 1 {
+		return ""
+	}
+
+	return spdxLicenses[p.Licenses[0]]
+}
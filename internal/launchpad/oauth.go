@@ -0,0 +1,165 @@
+package launchpad
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jnsgruk/releasegen/internal/credentials"
+)
+
+const (
+	// DefaultConsumerKey is the OAuth consumer key that identifies releasegen to Launchpad.
+	DefaultConsumerKey = "releasegen"
+
+	requestTokenURL   = "https://launchpad.net/+request-token"
+	authorizeTokenURL = "https://launchpad.net/+authorize-token"
+	accessTokenURL    = "https://launchpad.net/+access-token"
+)
+
+var (
+	// errInvalidOAuthCredentials is returned when stored OAuth credentials can't be parsed.
+	errInvalidOAuthCredentials = errors.New(
+		"launchpad credential must be in the form '<consumer key>:<token>:<token secret>'",
+	)
+	// ErrNotAuthorized is returned when exchanging a request token that the user hasn't authorised.
+	ErrNotAuthorized = errors.New("the request token has not been authorised on launchpad")
+)
+
+// OAuthCredentials are the OAuth 1.0 consumer key, token and token secret used to authenticate
+// with the Launchpad API. Launchpad only supports the PLAINTEXT signature method, and doesn't
+// use consumer secrets.
+type OAuthCredentials struct {
+	ConsumerKey string
+	Token       string
+	TokenSecret string
+}
+
+// ParseOAuthCredentials parses credentials in the form written by the String method.
+func ParseOAuthCredentials(s string) (*OAuthCredentials, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) != 3 || slices.Contains(parts, "") {
+		return nil, errInvalidOAuthCredentials
+	}
+
+	return &OAuthCredentials{ConsumerKey: parts[0], Token: parts[1], TokenSecret: parts[2]}, nil
+}
+
+// String returns a serialised form of the credentials, suitable for storing in a credential file.
+func (c *OAuthCredentials) String() string {
+	return strings.Join([]string{c.ConsumerKey, c.Token, c.TokenSecret}, ":")
+}
+
+// RequestToken obtains an unauthorised request token from Launchpad. The user must visit the
+// URL returned by AuthorizeURL and approve the token, before it is exchanged with Exchange.
+func RequestToken(ctx context.Context, consumerKey string) (*OAuthCredentials, error) {
+	values, err := postOAuthForm(ctx, requestTokenURL, url.Values{
+		"oauth_consumer_key":     {consumerKey},
+		"oauth_signature_method": {"PLAINTEXT"},
+		"oauth_signature":        {"&"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error requesting launchpad token: %w", err)
+	}
+
+	return &OAuthCredentials{
+		ConsumerKey: consumerKey,
+		Token:       values.Get("oauth_token"),
+		TokenSecret: values.Get("oauth_token_secret"),
+	}, nil
+}
+
+// AuthorizeURL returns the URL at which the user can authorise a request token. Only read
+// access, including to private data, is offered.
+func (c *OAuthCredentials) AuthorizeURL() string {
+	return fmt.Sprintf("%s?oauth_token=%s&allow_permission=READ_PRIVATE", authorizeTokenURL, url.QueryEscape(c.Token))
+}
+
+// Exchange swaps an authorised request token for an access token.
+func (c *OAuthCredentials) Exchange(ctx context.Context) (*OAuthCredentials, error) {
+	values, err := postOAuthForm(ctx, accessTokenURL, url.Values{
+		"oauth_consumer_key":     {c.ConsumerKey},
+		"oauth_token":            {c.Token},
+		"oauth_signature_method": {"PLAINTEXT"},
+		"oauth_signature":        {"&" + c.TokenSecret},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error exchanging launchpad token: %w", err)
+	}
+
+	access := &OAuthCredentials{
+		ConsumerKey: c.ConsumerKey,
+		Token:       values.Get("oauth_token"),
+		TokenSecret: values.Get("oauth_token_secret"),
+	}
+
+	credentials.Register(access.TokenSecret)
+
+	return access, nil
+}
+
+// sign adds an OAuth Authorization header to a request to the Launchpad API.
+func (c *OAuthCredentials) sign(req *http.Request) {
+	nonce := make([]byte, 16) //nolint:gomnd
+	_, _ = rand.Read(nonce)
+
+	params := []string{
+		`realm="https://api.launchpad.net/"`,
+		fmt.Sprintf(`oauth_consumer_key="%s"`, url.QueryEscape(c.ConsumerKey)),
+		fmt.Sprintf(`oauth_token="%s"`, url.QueryEscape(c.Token)),
+		`oauth_signature_method="PLAINTEXT"`,
+		fmt.Sprintf(`oauth_signature="%s"`, url.QueryEscape("&"+c.TokenSecret)),
+		fmt.Sprintf(`oauth_timestamp="%s"`, strconv.FormatInt(time.Now().Unix(), 10)),
+		fmt.Sprintf(`oauth_nonce="%s"`, hex.EncodeToString(nonce)),
+		`oauth_version="1.0"`,
+	}
+
+	req.Header.Set("Authorization", "OAuth "+strings.Join(params, ", "))
+}
+
+// postOAuthForm posts a form to one of Launchpad's OAuth endpoints, and parses the response.
+func postOAuthForm(ctx context.Context, endpoint string, form url.Values) (url.Values, error) {
+	client := &http.Client{Timeout: launchpadTimeout}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusUnauthorized {
+		return nil, ErrNotAuthorized
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, errUnexpectedStatusCode
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing launchpad oauth response: %w", err)
+	}
+
+	return values, nil
+}
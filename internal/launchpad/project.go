@@ -20,6 +20,13 @@ var (
 	errUnexpectedStatusCode = errors.New("unexpected HTTP status code")
 	// errFetchReadme is returned when a README could not be fetched or parsed.
 	errFetchReadme = errors.New("error getting README for repo")
+	// ErrUnauthorized is returned when Launchpad rejects the configured OAuth credentials.
+	ErrUnauthorized = errors.New("launchpad credentials are invalid or have been revoked")
+	// errNotPublic is returned when git.launchpad.net won't serve a repository's pages. Its pages
+	// can't be requested with OAuth credentials, so private repositories can't be read.
+	errNotPublic = errors.New(
+		"repository is not public on git.launchpad.net, which doesn't accept launchpad credentials",
+	)
 )
 
 // Project is a representation of a Launchpad Project.
@@ -27,8 +34,11 @@ type Project struct {
 	Name          string
 	defaultBranch string
 	tags          []*Tag
+	// moreTags is set if the project has more tags than were fetched.
+	moreTags bool
 
 	projectPage *goquery.Document
+	logPage     *goquery.Document
 }
 
 // Tags returns a list of tags for a Launchpad project.
@@ -80,24 +90,93 @@ func (p *Project) DefaultBranch(ctx context.Context) (string, error) {
 // GetNewCommits parses the git log page for a Launchpad project and returns the number of
 // commits that have happened on the default branch since the last tag.
 func (p *Project) NewCommits(ctx context.Context) (int, error) {
-	url := fmt.Sprintf("https://git.launchpad.net/%s/log", p.Name)
-
-	doc, err := parseWebpage(ctx, url)
-	if err != nil {
+	if err := p.fetchLogPage(ctx); err != nil {
 		return -1, err
 	}
 
-	commitTable := doc.Find("table.list")
+	return p.unreleasedRows().Length(), nil
+}
+
+// UnreleasedCommits parses the git log page for a Launchpad project and returns up to limit of
+// the commits that have happened on the default branch since the last tag, newest first. A
+// negative limit returns all of the commits.
+func (p *Project) UnreleasedCommits(ctx context.Context, limit int) ([]*LogCommit, error) {
+	if err := p.fetchLogPage(ctx); err != nil {
+		return nil, err
+	}
+
+	commits := []*LogCommit{}
+
+	p.unreleasedRows().EachWithBreak(func(_ int, row *goquery.Selection) bool {
+		if len(commits) == limit {
+			return false
+		}
+
+		// The columns of the log are the commit's age, message, author, files and lines changed.
+		link := row.Find("td").Eq(1).Find("a").First()
+		href, _ := link.Attr("href")
+		_, sha, _ := strings.Cut(href, "id=")
+		ts, _ := row.Find("td span[title]").First().Attr("title")
+
+		commit := &LogCommit{
+			Sha:     sha,
+			Subject: strings.TrimSpace(link.Text()),
+			Author:  strings.TrimSpace(row.Find("td").Eq(2).Text()),
+		}
+
+		// Long subjects are truncated in the log, but the full subject is in the title.
+		if title, ok := link.Attr("title"); ok {
+			commit.Subject = title
+		}
+
+		if timestamp, err := time.Parse("2006-01-02 15:04:05 -0700", ts); err == nil {
+			commit.Timestamp = &timestamp
+		}
+
+		commits = append(commits, commit)
+
+		return true
+	})
+
+	return commits, nil
+}
+
+// unreleasedRows returns the rows of the git log page for the commits on the default branch
+// since the last tag.
+func (p *Project) unreleasedRows() *goquery.Selection {
+	commitTable := p.logPage.Find("table.list")
 	branchDecorationRow := commitTable.Find("a.branch-deco").First().Parent().Parent().Parent()
 	tagDecorationRow := commitTable.Find("a.tag-deco").First().Parent().Parent().Parent()
 
 	// If the decorations are on the same row, there are no commits between last tag and branch.
 	if tagDecorationRow.Text() == branchDecorationRow.Text() {
-		return 0, nil
+		return commitTable.Find("tr").Slice(0, 0)
 	}
 
-	// Return the number of commits between the latest tag and the default branch.
-	return branchDecorationRow.NextUntilSelection(tagDecorationRow).Length() + 1, nil
+	// The commits between the latest tag and the default branch, including the branch's commit.
+	return branchDecorationRow.AddSelection(branchDecorationRow.NextUntilSelection(tagDecorationRow))
+}
+
+// LatestCommitTime parses the git log page for a Launchpad project and returns the time of the
+// latest commit on the default branch.
+func (p *Project) LatestCommitTime(ctx context.Context) (*time.Time, error) {
+	if err := p.fetchLogPage(ctx); err != nil {
+		return nil, err
+	}
+
+	// The first column of each row in the log holds the commit's age, with its full timestamp
+	// in the title attribute.
+	ts, exists := p.logPage.Find("table.list td span[title]").First().Attr("title")
+	if !exists {
+		return nil, errors.New("error finding latest commit for repository")
+	}
+
+	timestamp, err := time.Parse("2006-01-02 15:04:05 -0700", ts)
+	if err != nil {
+		return nil, errors.New("error parsing timestamp for latest Launchpad commit")
+	}
+
+	return &timestamp, nil
 }
 
 // fetchTags scrapes the launchpad project repo page for a list of git tags.
@@ -117,6 +196,7 @@ func (p *Project) fetchTags(ctx context.Context) ([]*Tag, error) {
 	tagRowHeader.NextUntil("tr.nohover").EachWithBreak(func(_ int, row *goquery.Selection) bool {
 		// Only get the first three tags.
 		if len(tags) == defaultNumTags {
+			p.moreTags = true
 			return false
 		}
 
@@ -165,7 +245,7 @@ func (p *Project) fetchProjectPage(ctx context.Context) error {
 
 		page, err := parseWebpage(ctx, projectURL)
 		if err != nil {
-			return errors.New("error fetching project page")
+			return fmt.Errorf("error fetching project page: %w", err)
 		}
 
 		p.projectPage = page
@@ -174,20 +254,40 @@ func (p *Project) fetchProjectPage(ctx context.Context) error {
 	return nil
 }
 
+// fetchLogPage fetches the project's git log page, and assigns the project's logPage to a parsed
+// representation of the page.
+func (p *Project) fetchLogPage(ctx context.Context) error {
+	if p.logPage == nil {
+		logURL := fmt.Sprintf("https://git.launchpad.net/%s/log", p.Name)
+
+		page, err := parseWebpage(ctx, logURL)
+		if err != nil {
+			return err
+		}
+
+		p.logPage = page
+	}
+
+	return nil
+}
+
 // fetchReadmeContent fetches the content of a README.md for a project if it has one.
 func (p *Project) fetchReadmeContent(ctx context.Context) (string, error) {
 	url := fmt.Sprintf("https://git.launchpad.net/%s/plain/README.md", p.Name)
-	client := &http.Client{Timeout: launchpadTimeout}
-	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 
-	res, err := client.Do(req)
+	res, err := get(ctx, url, nil)
 	if err != nil {
 		return "", errFetchReadme
 	}
 	defer res.Body.Close()
 
-	if res.StatusCode != http.StatusOK {
-		return "", errUnexpectedStatusCode
+	// Not every project has a README, so there is nothing to parse.
+	if res.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+
+	if err := checkGitStatus(res); err != nil {
+		return "", err
 	}
 
 	// Parse the useful information from the response.
@@ -199,10 +299,19 @@ func (p *Project) fetchReadmeContent(ctx context.Context) (string, error) {
 	return string(resBody), nil
 }
 
+// LogCommit is a representation of a commit listed in the git log of a Launchpad project.
+type LogCommit struct {
+	Sha       string
+	Subject   string
+	Author    string
+	Timestamp *time.Time
+}
+
 // Tag is a representation of a git Tag in Launchpad.
 type Tag struct {
 	Name      string
 	Commit    string
+	Message   string
 	Timestamp *time.Time
 
 	project string
@@ -215,7 +324,7 @@ func (t *Tag) Process(ctx context.Context) error {
 
 	doc, err := parseWebpage(ctx, url)
 	if err != nil {
-		return errors.New("error fetching commit page")
+		return fmt.Errorf("error fetching commit page: %w", err)
 	}
 
 	// Find the commit hash for the tag.
@@ -223,6 +332,11 @@ func (t *Tag) Process(ctx context.Context) error {
 	commit := commitTable.Find("a").First().Text()
 	t.Commit = commit
 
+	// Find the message of the commit, which is split into its subject and the rest of the message.
+	subject := strings.TrimSpace(doc.Find("div.commit-subject").First().Text())
+	message := strings.TrimSpace(doc.Find("div.commit-msg").First().Text())
+	t.Message = strings.TrimSpace(subject + "\n\n" + message)
+
 	// Find the timestamp for the tag/commit in question.
 	ts := commitTable.Find("td.right").First().Text()
 
@@ -239,17 +353,14 @@ func (t *Tag) Process(ctx context.Context) error {
 
 // parseWebpage fetches a URL and returns a goquery.Document for scraping.
 func parseWebpage(ctx context.Context, url string) (*goquery.Document, error) {
-	client := &http.Client{Timeout: launchpadTimeout}
-	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-
-	res, err := client.Do(req)
+	res, err := get(ctx, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching url %s: %w", url, err)
 	}
 	defer res.Body.Close()
 
-	if res.StatusCode != http.StatusOK {
-		return nil, errUnexpectedStatusCode
+	if err := checkGitStatus(res); err != nil {
+		return nil, err
 	}
 
 	doc, err := goquery.NewDocumentFromReader(res.Body)
@@ -259,3 +370,30 @@ func parseWebpage(ctx context.Context, url string) (*goquery.Document, error) {
 
 	return doc, nil
 }
+
+// checkStatus converts the status of a response from the Launchpad API into either nil,
+// ErrUnauthorized, or errUnexpectedStatusCode.
+func checkStatus(res *http.Response) error {
+	switch res.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrUnauthorized
+	default:
+		return fmt.Errorf("%w: %d", errUnexpectedStatusCode, res.StatusCode)
+	}
+}
+
+// checkGitStatus converts the status of a response from git.launchpad.net into either nil,
+// errNotPublic, or errUnexpectedStatusCode. Its requests are anonymous, so a refusal is never
+// due to the configured credentials.
+func checkGitStatus(res *http.Response) error {
+	switch res.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+		return errNotPublic
+	default:
+		return fmt.Errorf("%w: %d", errUnexpectedStatusCode, res.StatusCode)
+	}
+}
@@ -2,8 +2,10 @@ package launchpad
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	"github.com/jnsgruk/releasegen/internal/markup"
 	"github.com/jnsgruk/releasegen/internal/repos"
 )
 
@@ -12,6 +14,9 @@ type Repository struct {
 	Details       repos.RepoDetails
 	project       *Project
 	projectGroup  string
+	renderer      *markup.Renderer
+	maxUnreleased int                   // The maximum number of unreleased commits to list.
+	artifacts     *repos.ArtifactConfig // The artifacts declared in the config, if any.
 	defaultBranch string
 }
 
@@ -20,20 +25,40 @@ func (r *Repository) Process(ctx context.Context) error {
 	r.project = &Project{Name: r.Details.Name}
 
 	// Iterate over the tags in the Launchpad repo and add them to our repository's details.
+	// Private projects are listed by the API when authenticated, but git.launchpad.net won't
+	// serve their tags or commits, so they're reported as private with a warning, not an error.
 	err := r.processTags(ctx)
+	if errors.Is(err, errNotPublic) {
+		r.Details.Private = true
+		r.Details.AddWarning(sourceName, repos.StageTags, err)
+
+		return nil
+	}
+
 	if err != nil {
+		r.Details.AddError(sourceName, repos.StageTags, err)
 		return err
 	}
 
 	// Calculate the number of commits since the latest release.
 	err = r.processCommitsSinceRelease(ctx)
 	if err != nil {
+		r.Details.AddError(sourceName, repos.StageCommits, err)
 		return err
 	}
 
+	// Populate the time of the latest commit. The tag data is complete at this point, so
+	// problems with metadata are only warnings.
+	err = r.processLatestCommit(ctx)
+	if err != nil {
+		r.Details.AddWarning(sourceName, repos.StageMetadata, err)
+	}
+
 	// Populate the repository's README from Launchpad, parse any linked snaps, charms or CI actions.
+	// The tag data is complete at this point, so README problems are only warnings.
 	err = r.parseReadme(ctx, r.project)
 	if err != nil {
+		r.Details.AddWarning(sourceName, repos.StageReadme, err)
 		return err
 	}
 
@@ -48,24 +73,36 @@ func (r *Repository) processTags(ctx context.Context) error {
 		return err
 	}
 
-	if len(tags) == 0 {
-		return nil
+	// The default branch is read from the project page that lists the tags, so it's populated
+	// whether or not the repository has tags. Without tags, it's only needed as metadata.
+	err = r.processDefaultBranch(ctx)
+	if err != nil && len(tags) > 0 {
+		return err
 	}
 
-	err = r.processDefaultBranch(ctx)
 	if err != nil {
-		return err
+		r.Details.AddWarning(sourceName, repos.StageMetadata, err)
+	}
+
+	if len(tags) == 0 {
+		return nil
 	}
 
+	// Only the latest tags are fetched, so older tags are missing from the history.
+	r.Details.HistoryTruncated = r.project.moreTags
+
 	// Iterate over the tags in the Launchpad repo.
 	for _, t := range tags {
+		r.Details.ReleaseHistory = append(r.Details.ReleaseHistory, t.Timestamp.Unix())
 		r.Details.Tags = append(r.Details.Tags, &repos.Tag{
-			Name:       t.Name,
-			Sha:        t.Commit,
-			Body:       "",
-			Timestamp:  t.Timestamp.Unix(),
-			URL:        fmt.Sprintf("%s/tag/?h=%s", r.Details.URL, t.Name),
-			CompareURL: fmt.Sprintf("%s/diff/?id=%s&id2=%s", r.Details.URL, t.Commit, r.defaultBranch),
+			Name:         t.Name,
+			Sha:          t.Commit,
+			Body:         r.renderer.HTML(t.Message, markup.Ref{Repo: r.Details.Name}),
+			BodyMarkdown: r.renderer.Markdown(t.Message),
+			Summary:      markup.Summary(t.Message),
+			Timestamp:    t.Timestamp.Unix(),
+			URL:          fmt.Sprintf("%s/tag/?h=%s", r.Details.URL, t.Name),
+			CompareURL:   fmt.Sprintf("%s/diff/?id=%s&id2=%s", r.Details.URL, t.Commit, r.defaultBranch),
 		})
 	}
 
@@ -81,12 +118,14 @@ func (r *Repository) processDefaultBranch(ctx context.Context) error {
 	}
 
 	r.defaultBranch = defaultBranch
+	r.Details.DefaultBranch = defaultBranch
 
 	return nil
 }
 
 // processCommitsSinceRelease calculates the number of commits that have occurred on the default
-// branch of the repository since the last release, and populates the information in r.Details.
+// branch of the repository since the last release, and populates the information in r.Details,
+// along with a list of the most recent of those commits.
 func (r *Repository) processCommitsSinceRelease(ctx context.Context) error {
 	newCommits, err := r.project.NewCommits(ctx)
 	if err != nil {
@@ -95,6 +134,46 @@ func (r *Repository) processCommitsSinceRelease(ctx context.Context) error {
 
 	r.Details.NewCommits = newCommits
 
+	// Fetch all of the unreleased commits, to find the oldest, but only list the newest.
+	commits, err := r.project.UnreleasedCommits(ctx, -1)
+	if err != nil {
+		return err
+	}
+
+	if len(commits) > 0 && commits[len(commits)-1].Timestamp != nil {
+		r.Details.OldestUnreleased = commits[len(commits)-1].Timestamp.Unix()
+	}
+
+	r.Details.Unreleased = []*repos.Unreleased{}
+
+	for _, c := range commits[:min(len(commits), r.maxUnreleased)] {
+		unreleased := &repos.Unreleased{
+			Sha:     c.Sha,
+			Subject: c.Subject,
+			Author:  c.Author,
+			URL:     fmt.Sprintf("%s/commit/?id=%s", r.Details.URL, c.Sha),
+		}
+
+		if c.Timestamp != nil {
+			unreleased.Timestamp = c.Timestamp.Unix()
+		}
+
+		r.Details.Unreleased = append(r.Details.Unreleased, unreleased)
+	}
+
+	return nil
+}
+
+// processLatestCommit populates the time of the latest commit on the default branch, which is
+// the closest equivalent to the time a Github repository was last pushed to.
+func (r *Repository) processLatestCommit(ctx context.Context) error {
+	latest, err := r.project.LatestCommitTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Details.PushedAt = latest.Unix()
+
 	return nil
 }
 
@@ -103,14 +182,11 @@ func (r *Repository) processCommitsSinceRelease(ctx context.Context) error {
 func (r *Repository) parseReadme(ctx context.Context, project *Project) error {
 	// Get contents of the README as a string.
 	readmeContent, err := project.fetchReadmeContent(ctx)
-	if err != nil {
-		return err
-	}
 
-	// Parse contents of README to identify associated snaps and charms.
+	// Parse contents of README to identify associated snaps and charms. Artifacts declared in the
+	// config don't depend on the README, so are populated even if it couldn't be fetched.
 	readme := &repos.Readme{Body: readmeContent}
-	r.Details.Snap = readme.LinkedSnap(ctx)
-	r.Details.Charm = readme.LinkedCharm(ctx)
+	readme.PopulateArtifacts(ctx, &r.Details, r.artifacts)
 
-	return nil
+	return err
 }
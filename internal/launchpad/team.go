@@ -11,19 +11,23 @@ import (
 )
 
 // FetchProjectGroupRepos creates a slice of RepoDetails types representing the repos
-// associated with a given ProjectGroup in Launchpad.
-func FetchProjectGroupRepos(projectGroup string, config Config) ([]repos.RepoDetails, error) {
-	pgRepos := []repos.RepoDetails{}
-
-	lpRepos, err := getProjectGroupRepos(projectGroup, config, &pgRepos)
+// associated with a given ProjectGroup in Launchpad. Each repo is claimed by the team in the
+// index, and is only processed if it's not already in the index.
+func FetchProjectGroupRepos(
+	projectGroup string, config Config, index *repos.Index, team string,
+) ([]*repos.RepoDetails, error) {
+	pgRepos := []*repos.RepoDetails{}
+
+	lpRepos, err := getProjectGroupRepos(projectGroup, config, index, team)
 	if err != nil {
 		return nil, err
 	}
 
-	// Iterate over repos, add only those that have tags to the Team's list of repos
+	// Iterate over repos, add only those that have tags, that couldn't be fully processed, or
+	// that are private, to the Team's list of repos
 	for _, r := range lpRepos {
-		if len(r.Details.Tags) > 0 {
-			pgRepos = append(pgRepos, r.Details)
+		if len(r.Tags) > 0 || len(r.Errors) > 0 || r.Private {
+			pgRepos = append(pgRepos, r)
 		}
 	}
 
@@ -32,48 +36,69 @@ func FetchProjectGroupRepos(projectGroup string, config Config) ([]repos.RepoDet
 
 // getProjectGroupRepos fetches information about repositories from Launchpad and returns it as
 // a slice of structs in the right format for releasegen.
-func getProjectGroupRepos(pg string, config Config, pgRepos *[]repos.RepoDetails) ([]*Repository, error) {
+func getProjectGroupRepos(pg string, config Config, index *repos.Index, team string) ([]*repos.RepoDetails, error) {
 	ctx := context.Background()
-	lpRepos := []*Repository{}
 
-	projects, err := enumerateProjectGroup(ctx, pg)
+	projects, err := enumerateProjectGroup(ctx, pg, config)
 	if err != nil {
 		return nil, fmt.Errorf("error enumerating project group '%s': %w", pg, err)
 	}
 
+	renderer := config.Renderer()
+
+	// Each project's details are stored at the project's index, to preserve the project order.
+	results := make([]*repos.RepoDetails, len(projects))
+
 	var wg sync.WaitGroup
 
-	for _, project := range projects {
+	for i, project := range projects {
 		p := project
-		// Check if the name of the repository is in the ignore list for the team
-		if slices.Contains(config.IgnoredRepos, p) || repos.RepoInSlice(*pgRepos, p) {
+		// Launchpad project names are unique across Launchpad, so the ID has no owner.
+		id := repos.ID(sourceName, "", p.Name)
+
+		// Check if the name or ID of the repository is in the ignore list for the team
+		if config.ignores.MatchesRepo(id, p.Name) {
 			continue
 		}
 
 		// Create a new repository, add to the list of repos for the project group
 		repo := &Repository{
 			Details: repos.RepoDetails{
-				Name: p,
-				URL:  fmt.Sprintf("https://git.launchpad.net/%s", p),
+				ID:          id,
+				Source:      sourceName,
+				Name:        p.Name,
+				URL:         fmt.Sprintf("https://git.launchpad.net/%s", p.Name),
+				Description: p.Summary,
+				Homepage:    p.Homepage,
+				Language:    p.Language,
+				License:     p.spdxLicense(),
 			},
-			projectGroup: pg,
+			projectGroup:  pg,
+			renderer:      renderer,
+			maxUnreleased: config.MaxUnreleased(),
+			artifacts:     config.Artifacts().For(id),
 		}
-		lpRepos = append(lpRepos, repo)
 
 		wg.Add(1)
 
 		go func() {
 			defer wg.Done()
-			log.Printf("processing launchpad repo: %s/%s\n", repo.projectGroup, repo.Details.Name)
 
-			err := repo.Process(ctx)
-			if err != nil {
-				log.Printf("error populating repo %s from launchpad: %s", repo.Details.Name, err.Error())
-			}
+			results[i] = index.Fetch(repo.Details.ID, team, func() *repos.RepoDetails {
+				log.Printf("processing launchpad repo: %s/%s\n", repo.projectGroup, repo.Details.Name)
+
+				err := repo.Process(ctx)
+				if err != nil {
+					log.Printf("error populating repo %s from launchpad: %s", repo.Details.Name, err.Error())
+				}
+
+				return &repo.Details
+			})
 		}()
 	}
 
 	wg.Wait()
 
-	return lpRepos, nil
+	// Remove the gaps left by ignored projects.
+	return slices.DeleteFunc(results, func(r *repos.RepoDetails) bool { return r == nil }), nil
 }
@@ -0,0 +1,367 @@
+package markup
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// The kinds of reference that can be linked in release bodies and commit messages. Each has a
+// URL template, which may use the placeholders described in placeholders.
+const (
+	// PullRequest links full pull request URLs, which are shortened to '#<number>'.
+	PullRequest = "pull-request"
+	// Issue links '#<number>' and '<owner>/<repo>#<number>' references. On Github, issue URLs
+	// redirect to the pull request when the number belongs to one.
+	Issue = "issue"
+	// MergeRequest links GitLab style '!<number>' merge request references, and shortens GitLab
+	// merge request URLs. It has no default template, as neither Github nor Launchpad use them.
+	MergeRequest = "merge-request"
+	// Bug links Launchpad style 'LP: #<number>' bug references.
+	Bug = "bug"
+	// Commit links abbreviated or full commit SHAs.
+	Commit = "commit"
+	// User links '@<user>' mentions.
+	User = "user"
+)
+
+// placeholders are the placeholders that may be used in URL templates.
+//
+//nolint:gochecknoglobals
+var placeholders = []string{"{base}", "{owner}", "{repo}", "{number}", "{sha}", "{user}"}
+
+// defaultTemplates are the URL templates used for each source, unless overridden in the config.
+// Kinds without a template for a source aren't linked in that source's bodies.
+//
+//nolint:gochecknoglobals
+var defaultTemplates = map[string]Templates{
+	"github": {
+		PullRequest: "{base}/{owner}/{repo}/pull/{number}",
+		Issue:       "{base}/{owner}/{repo}/issues/{number}",
+		Commit:      "{base}/{owner}/{repo}/commit/{sha}",
+		User:        "{base}/{user}",
+	},
+	"launchpad": {
+		Bug:    "https://bugs.launchpad.net/bugs/{number}",
+		Commit: "https://git.launchpad.net/{repo}/commit/?id={sha}",
+	},
+}
+
+var (
+	// urlRegexp is used to find bare URLs, which are either shortened if they refer to a pull
+	// request, issue or merge request, or otherwise left untouched.
+	urlRegexp = regexp.MustCompile(`https?://[^\s<>()\[\]"'` + "`" + `]+`)
+	// protectedRegexp is used to find code, existing links and HTML tags, which are never changed.
+	protectedRegexp = regexp.MustCompile("(?s)```.*?```|`[^`\\n]*`|\\[[^\\]]*\\]\\([^)]*\\)|<a\\b.*?</a>|<[^>]+>")
+	// crossRefRegexp is used to find references such as 'acme/tools#34'.
+	crossRefRegexp = regexp.MustCompile(`\b([\w.-]+)/([\w.-]+)#([0-9]+)\b`)
+	// shortRefRegexp is used to find references such as '#34'. HTML entities such as '&#34;'
+	// aren't references.
+	shortRefRegexp = regexp.MustCompile(`(\A|[^\w&/#])#([0-9]+)\b`)
+	// mergeRequestRegexp is used to find GitLab merge request references such as '!34'.
+	mergeRequestRegexp = regexp.MustCompile(`(\A|[^\w!])!([0-9]+)\b`)
+	// bugRegexp is used to find Launchpad bug references such as 'LP: #34' or 'LP: #34, #35'.
+	bugRegexp = regexp.MustCompile(`\bLP:\s*#[0-9]+(?:\s*,\s*#[0-9]+)*`)
+	// bugNumberRegexp is used to find each of the bug numbers in a Launchpad bug reference.
+	bugNumberRegexp = regexp.MustCompile(`#([0-9]+)`)
+	// indentedCodeRegexp is used to find indented code blocks, whose lines are indented by four
+	// spaces or a tab, and which follow a blank line or start the body.
+	indentedCodeRegexp = regexp.MustCompile(`(?:\A|\n[ \t]*\n)(?:(?: {4}|\t)[^\n]*(?:\n|\z))+`)
+	// shaRegexp is used to find candidate commit SHAs, which are between 7 and 40 hex digits.
+	// Only those accepted by isSHA are linked.
+	shaRegexp = regexp.MustCompile(`\b[0-9a-f]{7,40}\b`)
+	// userRegexp is used to find Twitter/Github style mentions such as '@JoeBloggs'.
+	userRegexp = regexp.MustCompile(`(\A|\s)@([\w-]+)`)
+	// placeholderRegexp is used to find the placeholders in URL templates.
+	placeholderRegexp = regexp.MustCompile(`\{[^}]*\}`)
+	// jiraProjectRegexp is used to validate Jira project keys.
+	jiraProjectRegexp = regexp.MustCompile(`^[A-Z][A-Z0-9_]+$`)
+)
+
+// Templates maps each kind of reference to the URL template used to link it. An empty template
+// disables linking for that kind of reference.
+type Templates map[string]string
+
+// Config contains fields used in releasegen's config.yaml file to configure how references in
+// release bodies and commit messages are linked. Templates for each source override the
+// defaults for that source.
+type Config struct {
+	Github    Templates   `mapstructure:"github"`
+	Launchpad Templates   `mapstructure:"launchpad"`
+	Jira      *JiraConfig `mapstructure:"jira"`
+}
+
+// JiraConfig configures linking of Jira style issue keys, such as 'ABC-123'. Only keys in the
+// listed projects are linked, as many other words look like issue keys, such as 'UTF-8'.
+type JiraConfig struct {
+	URL      string   `mapstructure:"url"`
+	Projects []string `mapstructure:"projects"`
+}
+
+// Validate checks that each template is for a known kind of reference, and only uses known
+// placeholders, and that the Jira config is complete.
+func (c *Config) Validate() error {
+	kinds := []string{PullRequest, Issue, MergeRequest, Bug, Commit, User}
+
+	for source, templates := range map[string]Templates{"github": c.Github, "launchpad": c.Launchpad} {
+		for kind, template := range templates {
+			if !slices.Contains(kinds, kind) {
+				return fmt.Errorf("unknown kind of link '%s' for %s", kind, source)
+			}
+
+			if err := validateTemplate(template, placeholders); err != nil {
+				return fmt.Errorf("invalid %s link template for %s: %w", kind, source, err)
+			}
+		}
+	}
+
+	if c.Jira == nil {
+		return nil
+	}
+
+	if !strings.Contains(c.Jira.URL, "{key}") {
+		return errors.New("jira link url must contain the '{key}' placeholder")
+	}
+
+	if err := validateTemplate(c.Jira.URL, []string{"{key}"}); err != nil {
+		return fmt.Errorf("invalid jira link url: %w", err)
+	}
+
+	if len(c.Jira.Projects) == 0 {
+		return errors.New("jira links must list at least one project")
+	}
+
+	for _, p := range c.Jira.Projects {
+		if !jiraProjectRegexp.MatchString(p) {
+			return fmt.Errorf("invalid jira project key '%s'", p)
+		}
+	}
+
+	return nil
+}
+
+// validateTemplate checks that a template is an absolute URL, or empty, and that it only
+// contains the allowed placeholders.
+func validateTemplate(template string, allowed []string) error {
+	if template == "" {
+		return nil
+	}
+
+	if !strings.HasPrefix(template, "https://") && !strings.HasPrefix(template, "http://") &&
+		!strings.HasPrefix(template, "{base}") {
+		return fmt.Errorf("template '%s' is not an absolute url", template)
+	}
+
+	for _, p := range placeholderRegexp.FindAllString(template, -1) {
+		if !slices.Contains(allowed, p) {
+			return fmt.Errorf("unknown placeholder '%s' in template '%s'", p, template)
+		}
+	}
+
+	return nil
+}
+
+// Linker links references in the release bodies and commit messages from a single source.
+type Linker struct {
+	templates Templates
+	jira      *regexp.Regexp
+	jiraURL   string
+}
+
+// Linker returns a Linker for the named source, such as 'github', using the default templates
+// for the source, overridden by any templates in the config.
+func (c *Config) Linker(source string) *Linker {
+	templates := Templates{}
+	maps.Copy(templates, defaultTemplates[source])
+
+	switch source {
+	case "github":
+		maps.Copy(templates, c.Github)
+	case "launchpad":
+		maps.Copy(templates, c.Launchpad)
+	}
+
+	linker := &Linker{templates: templates}
+
+	if c.Jira != nil && len(c.Jira.Projects) > 0 {
+		linker.jira = regexp.MustCompile(`\b(?:` + strings.Join(c.Jira.Projects, "|") + `)-[0-9]+\b`)
+		linker.jiraURL = c.Jira.URL
+	}
+
+	return linker
+}
+
+// Ref identifies the repository that a body belongs to, which relative references such as '#34'
+// refer to. Base is the web URL of the forge, such as 'https://github.com'.
+type Ref struct {
+	Base  string
+	Owner string
+	Repo  string
+}
+
+// rule finds one kind of reference, and replaces a match with its link. Replace is given the
+// submatches, and returns the match unchanged for references that shouldn't be linked.
+type rule struct {
+	re      *regexp.Regexp
+	replace func(m []string) string
+}
+
+// match is a single match of a rule in a body.
+type match struct {
+	start, end, priority int
+	replacement          string
+}
+
+// Linkify replaces the references in a Markdown body with HTML links. Code, existing links and
+// HTML tags are left untouched, and a reference is never linked more than once.
+func (l *Linker) Linkify(body string, ref Ref) string {
+	// Rules earlier in the list take precedence over later rules that match at the same place.
+	rules := []rule{
+		{protectedRegexp, func(m []string) string { return m[0] }},
+		{indentedCodeRegexp, func(m []string) string { return m[0] }},
+		{urlRegexp, func(m []string) string { return l.shortenURL(m[0], ref) }},
+		{bugRegexp, func(m []string) string { return l.linkBugs(m[0]) }},
+		{crossRefRegexp, func(m []string) string {
+			return l.link(Issue, m[0], m[0], Ref{Base: ref.Base, Owner: m[1], Repo: m[2]}, "{number}", m[3])
+		}},
+		{shortRefRegexp, func(m []string) string {
+			return m[1] + l.link(Issue, "#"+m[2], m[0][len(m[1]):], ref, "{number}", m[2])
+		}},
+		{mergeRequestRegexp, func(m []string) string {
+			return m[1] + l.link(MergeRequest, "!"+m[2], m[0][len(m[1]):], ref, "{number}", m[2])
+		}},
+		{userRegexp, func(m []string) string {
+			return m[1] + l.link(User, "@"+m[2], m[0][len(m[1]):], ref, "{user}", m[2])
+		}},
+		{shaRegexp, func(m []string) string {
+			if !isSHA(m[0]) {
+				return m[0]
+			}
+
+			return l.link(Commit, m[0][:7], m[0], ref, "{sha}", m[0])
+		}},
+	}
+
+	if l.jira != nil {
+		rules = append(rules, rule{l.jira, func(m []string) string {
+			return fmt.Sprintf(`<a target="_blank" href="%s">%s</a>`, strings.ReplaceAll(l.jiraURL, "{key}", m[0]), m[0])
+		}})
+	}
+
+	matches := []match{}
+
+	for priority, r := range rules {
+		for _, loc := range r.re.FindAllStringSubmatchIndex(body, -1) {
+			submatches := make([]string, len(loc)/2)
+			for i := range submatches {
+				if loc[2*i] >= 0 {
+					submatches[i] = body[loc[2*i]:loc[2*i+1]]
+				}
+			}
+
+			matches = append(matches, match{loc[0], loc[1], priority, r.replace(submatches)})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].start != matches[j].start {
+			return matches[i].start < matches[j].start
+		}
+
+		return matches[i].priority < matches[j].priority
+	})
+
+	// Build the output from the earliest of any overlapping matches.
+	var sb strings.Builder
+
+	pos := 0
+
+	for _, m := range matches {
+		if m.start < pos {
+			continue
+		}
+
+		sb.WriteString(body[pos:m.start])
+		sb.WriteString(m.replacement)
+		pos = m.end
+	}
+
+	sb.WriteString(body[pos:])
+
+	return sb.String()
+}
+
+// isSHA reports whether a string of hex digits found by shaRegexp is likely to be a commit SHA.
+// Dates such as '20231015', and words made of the letters a-f such as 'deadbeef', aren't: a SHA
+// must contain at least one digit and one of the letters a-f.
+func isSHA(s string) bool {
+	return strings.ContainsAny(s, "0123456789") && strings.ContainsAny(s, "abcdef")
+}
+
+// link returns an HTML link with the given text to the URL for a kind of reference, or the
+// original text if the kind of reference isn't linked.
+func (l *Linker) link(kind, text, original string, ref Ref, placeholder, value string) string {
+	template := l.templates[kind]
+	if template == "" {
+		return original
+	}
+
+	href := strings.NewReplacer(
+		"{base}", ref.Base, "{owner}", ref.Owner, "{repo}", ref.Repo, placeholder, value,
+	).Replace(template)
+
+	return fmt.Sprintf(`<a target="_blank" href="%s">%s</a>`, href, text)
+}
+
+// linkBugs links each of the bug numbers in a Launchpad bug reference.
+func (l *Linker) linkBugs(reference string) string {
+	return bugNumberRegexp.ReplaceAllStringFunc(reference, func(s string) string {
+		return l.link(Bug, s, s, Ref{}, "{number}", s[1:])
+	})
+}
+
+// shortenURL replaces the URL of a pull request, issue or merge request on the repository's
+// forge with a link such as '#34', or 'acme/tools#34' if it's in another repository. Other URLs
+// are returned unchanged.
+func (l *Linker) shortenURL(raw string, ref Ref) string {
+	// Trailing punctuation is more likely to end a sentence than to be part of the URL.
+	trimmed := strings.TrimRight(raw, ".,;:!?")
+	suffix := raw[len(trimmed):]
+
+	u, err := url.Parse(trimmed)
+	if err != nil || ref.Base == "" || !strings.HasPrefix(trimmed, ref.Base+"/") {
+		return raw
+	}
+
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+
+	// Merge request URLs have a '-' path segment, such as '/acme/tools/-/merge_requests/34'.
+	if len(parts) == 5 && parts[2] == "-" {
+		parts = append(parts[:2], parts[3:]...)
+	}
+
+	if len(parts) != 4 || strings.Trim(parts[3], "0123456789") != "" {
+		return raw
+	}
+
+	kinds := map[string]struct {
+		kind, prefix string
+	}{"pull": {PullRequest, "#"}, "issues": {Issue, "#"}, "merge_requests": {MergeRequest, "!"}}
+
+	k, ok := kinds[parts[2]]
+	if !ok || l.templates[k.kind] == "" {
+		return raw
+	}
+
+	text := k.prefix + parts[3]
+	if parts[0] != ref.Owner || parts[1] != ref.Repo {
+		text = parts[0] + "/" + parts[1] + text
+	}
+
+	return fmt.Sprintf(`<a target="_blank" href="%s">%s</a>`, trimmed, text) + suffix
+}
@@ -0,0 +1,155 @@
+package markup
+
+import "testing"
+
+func TestLinkify(t *testing.T) {
+	github := Ref{Base: "https://github.com", Owner: "acme", Repo: "tools"}
+	launchpad := Ref{Repo: "tools"}
+	config := &Config{Jira: &JiraConfig{URL: "https://acme.atlassian.net/browse/{key}", Projects: []string{"ABC"}}}
+
+	// link returns the HTML link that Linkify replaces a reference with.
+	link := func(href, text string) string { return `<a target="_blank" href="` + href + `">` + text + `</a>` }
+
+	tests := []struct {
+		name   string
+		source string
+		ref    Ref
+		body   string
+		want   string
+	}{
+		{
+			name:   "issue",
+			source: "github",
+			ref:    github,
+			body:   "Fixes #34.",
+			want:   "Fixes " + link("https://github.com/acme/tools/issues/34", "#34") + ".",
+		},
+		{
+			name:   "cross repository issue",
+			source: "github",
+			ref:    github,
+			body:   "See acme/other#12",
+			want:   "See " + link("https://github.com/acme/other/issues/12", "acme/other#12"),
+		},
+		{
+			name:   "pull request url",
+			source: "github",
+			ref:    github,
+			body:   "In https://github.com/acme/tools/pull/35.",
+			want:   "In " + link("https://github.com/acme/tools/pull/35", "#35") + ".",
+		},
+		{
+			name:   "other url",
+			source: "github",
+			ref:    github,
+			body:   "See https://example.com/docs#34",
+			want:   "See https://example.com/docs#34",
+		},
+		{
+			name:   "user",
+			source: "github",
+			ref:    github,
+			body:   "Thanks @JoeBloggs, not joe@example.com",
+			want:   "Thanks " + link("https://github.com/JoeBloggs", "@JoeBloggs") + ", not joe@example.com",
+		},
+		{
+			name:   "commit",
+			source: "github",
+			ref:    github,
+			body:   "Reverts 1a2b3c4d5e6f",
+			want:   "Reverts " + link("https://github.com/acme/tools/commit/1a2b3c4d5e6f", "1a2b3c4"),
+		},
+		{
+			name:   "not commits",
+			source: "github",
+			ref:    github,
+			body:   "Released 20231015 after deadbeef",
+			want:   "Released 20231015 after deadbeef",
+		},
+		{
+			name:   "html entity",
+			source: "github",
+			ref:    github,
+			body:   "Fix &#34;quotes&#34;",
+			want:   "Fix &#34;quotes&#34;",
+		},
+		{
+			name:   "code and links",
+			source: "github",
+			ref:    github,
+			body:   "`#34` and [#35](https://example.com) and\n```\n#36\n```",
+			want:   "`#34` and [#35](https://example.com) and\n```\n#36\n```",
+		},
+		{
+			name:   "indented code",
+			source: "github",
+			ref:    github,
+			body:   "Run:\n\n    fix #34\n\nfor #35",
+			want:   "Run:\n\n    fix #34\n\nfor " + link("https://github.com/acme/tools/issues/35", "#35"),
+		},
+		{
+			name:   "merge requests aren't linked by default",
+			source: "github",
+			ref:    github,
+			body:   "See !12",
+			want:   "See !12",
+		},
+		{
+			name:   "launchpad bugs",
+			source: "launchpad",
+			ref:    launchpad,
+			body:   "LP: #1234, #5678",
+			want: "LP: " + link("https://bugs.launchpad.net/bugs/1234", "#1234") + ", " +
+				link("https://bugs.launchpad.net/bugs/5678", "#5678"),
+		},
+		{
+			name:   "launchpad issues aren't linked",
+			source: "launchpad",
+			ref:    launchpad,
+			body:   "Fixes #34",
+			want:   "Fixes #34",
+		},
+		{
+			name:   "jira",
+			source: "github",
+			ref:    github,
+			body:   "Part of ABC-123, not UTF-8",
+			want:   "Part of " + link("https://acme.atlassian.net/browse/ABC-123", "ABC-123") + ", not UTF-8",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := config.Linker(tt.source).Linkify(tt.body, tt.ref); got != tt.want {
+				t.Errorf("Linkify(%q)\n got %q\nwant %q", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *Config
+		wantErr bool
+	}{
+		{"empty", &Config{}, false},
+		{"github template", &Config{Github: Templates{Issue: "{base}/{owner}/{repo}/issues/{number}"}}, false},
+		{"disabled kind", &Config{Launchpad: Templates{Commit: ""}}, false},
+		{"unknown kind", &Config{Github: Templates{"discussion": "{base}/{number}"}}, true},
+		{"unknown placeholder", &Config{Github: Templates{Issue: "{base}/{project}/{number}"}}, true},
+		{"relative template", &Config{Github: Templates{Issue: "/issues/{number}"}}, true},
+		{"jira", &Config{Jira: &JiraConfig{URL: "https://acme.atlassian.net/browse/{key}", Projects: []string{"ABC"}}}, false},
+		{"jira without key", &Config{Jira: &JiraConfig{URL: "https://acme.atlassian.net/browse", Projects: []string{"ABC"}}}, true},
+		{"jira without projects", &Config{Jira: &JiraConfig{URL: "https://acme.atlassian.net/browse/{key}"}}, true},
+		{"jira invalid project", &Config{Jira: &JiraConfig{URL: "https://acme.atlassian.net/browse/{key}", Projects: []string{"abc"}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+}
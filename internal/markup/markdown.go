@@ -0,0 +1,43 @@
+package markup
+
+import "github.com/gomarkdown/markdown"
+
+// ToHTML renders a Markdown string, such as the body of a release, as HTML.
+func ToHTML(body string) string {
+	md := []byte(body)
+	normalised := markdown.NormalizeNewlines(md)
+
+	return string(markdown.ToHTML(normalised, nil, nil))
+}
+
+// Renderer renders the release bodies and commit messages from a single source as sanitised
+// HTML, linking any references.
+type Renderer struct {
+	linker *Linker
+	policy *Policy
+}
+
+// NewRenderer returns a Renderer that links references with the linker, and sanitises the
+// rendered HTML according to the policy.
+func NewRenderer(linker *Linker, policy *Policy) *Renderer {
+	return &Renderer{linker: linker, policy: policy}
+}
+
+// HTML renders a Markdown body as sanitised HTML, or returns an empty string if the policy only
+// outputs Markdown. References such as '#34' are relative to the repository identified by ref.
+func (r *Renderer) HTML(body string, ref Ref) string {
+	if r.policy.Output == OutputMarkdown {
+		return ""
+	}
+
+	return r.policy.Sanitise(ToHTML(r.linker.Linkify(body, ref)))
+}
+
+// Markdown returns the raw Markdown body, or an empty string if the policy only outputs HTML.
+func (r *Renderer) Markdown(body string) string {
+	if r.policy.Output == OutputHTML {
+		return ""
+	}
+
+	return body
+}
@@ -0,0 +1,278 @@
+package markup
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// The supported values of the images and code options in a Policy.
+const (
+	// Allow keeps images or code blocks in the sanitised HTML.
+	Allow = "allow"
+	// Strip removes images from the sanitised HTML.
+	Strip = "strip"
+	// Plain keeps the text of code blocks, but removes the code and pre elements.
+	Plain = "plain"
+)
+
+// The supported values of the output option in a Policy.
+const (
+	// OutputHTML outputs release bodies and commit messages as sanitised HTML only.
+	OutputHTML = "html"
+	// OutputMarkdown outputs release bodies and commit messages as raw Markdown only.
+	OutputMarkdown = "markdown"
+	// OutputBoth outputs release bodies and commit messages as both sanitised HTML and the raw
+	// Markdown they were rendered from. This is the default.
+	OutputBoth = "both"
+)
+
+const (
+	defaultLinkTarget = "_blank"
+	defaultLinkRel    = "noopener noreferrer"
+)
+
+// allowedElements are the elements kept by the sanitiser, along with their allowed attributes.
+// Other elements are removed, but their content is kept unless they're in droppedElements.
+//
+//nolint:gochecknoglobals
+var allowedElements = map[atom.Atom][]string{
+	atom.A: {"href", "title"}, atom.Abbr: {"title"}, atom.B: nil, atom.Blockquote: nil, atom.Br: nil,
+	atom.Dd: nil, atom.Del: nil, atom.Details: nil, atom.Dl: nil, atom.Dt: nil, atom.Em: nil,
+	atom.H1: nil, atom.H2: nil, atom.H3: nil, atom.H4: nil, atom.H5: nil, atom.H6: nil, atom.Hr: nil,
+	atom.I: nil, atom.Ins: nil, atom.Kbd: nil, atom.Li: nil, atom.Ol: {"start"}, atom.P: nil,
+	atom.S: nil, atom.Strong: nil, atom.Sub: nil, atom.Summary: nil, atom.Sup: nil,
+	atom.Table: nil, atom.Tbody: nil, atom.Td: {"align", "colspan", "rowspan"},
+	atom.Th: {"align", "colspan", "rowspan"}, atom.Thead: nil, atom.Tr: nil, atom.Ul: nil,
+}
+
+// droppedElements are removed by the sanitiser along with their content.
+//
+//nolint:gochecknoglobals
+var droppedElements = []atom.Atom{
+	atom.Script, atom.Style, atom.Iframe, atom.Object, atom.Embed, atom.Noscript, atom.Template,
+	atom.Textarea, atom.Select, atom.Svg, atom.Math, atom.Frameset, atom.Title,
+}
+
+// codeClassRegexp is used to find the classes that Markdown renderers give code blocks to mark
+// their language, such as 'language-go'.
+var codeClassRegexp = regexp.MustCompile(`^language-[\w+#.-]+$`)
+
+// Policy contains fields used in releasegen's config.yaml file to configure how the HTML
+// rendered from release bodies and commit messages is sanitised.
+type Policy struct {
+	LinkTarget string `mapstructure:"link-target"`
+	LinkRel    string `mapstructure:"link-rel"`
+	Images     string `mapstructure:"images"`
+	Code       string `mapstructure:"code"`
+	Output     string `mapstructure:"output"`
+}
+
+// Validate checks that the options in the policy are known.
+func (p *Policy) Validate() error {
+	options := []struct {
+		name, value string
+		allowed     []string
+	}{
+		{"link-target", p.LinkTarget, []string{"", "none", "_blank", "_self", "_parent", "_top"}},
+		{"images", p.Images, []string{"", Allow, Strip}},
+		{"code", p.Code, []string{"", Allow, Plain}},
+		{"output", p.Output, []string{"", OutputHTML, OutputMarkdown, OutputBoth}},
+	}
+
+	for _, o := range options {
+		if !slices.Contains(o.allowed, o.value) {
+			return fmt.Errorf("unknown html %s '%s'", o.name, o.value)
+		}
+	}
+
+	return nil
+}
+
+// Sanitise removes everything from a fragment of HTML that isn't in the allow-list, such as
+// scripts, event handlers and links with unsafe schemes. Links are given the policy's target
+// and rel attributes.
+func (p *Policy) Sanitise(fragment string) string {
+	context := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+
+	nodes, err := html.ParseFragment(strings.NewReader(fragment), context)
+	if err != nil {
+		return html.EscapeString(fragment)
+	}
+
+	var sb strings.Builder
+
+	for _, n := range nodes {
+		p.sanitiseNode(&sb, n)
+	}
+
+	return sb.String()
+}
+
+// sanitiseNode writes the sanitised form of a node, and its children, to sb.
+func (p *Policy) sanitiseNode(sb *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		sb.WriteString(html.EscapeString(n.Data))
+		return
+	case html.ElementNode:
+	default:
+		// Comments and doctypes are dropped.
+		return
+	}
+
+	if slices.Contains(droppedElements, n.DataAtom) {
+		return
+	}
+
+	attrs, ok := p.allowedAttrs(n)
+	if !ok {
+		// The element isn't allowed, but its content is.
+		p.sanitiseChildren(sb, n)
+		return
+	}
+
+	sb.WriteString("<" + n.Data)
+
+	for _, a := range attrs {
+		fmt.Fprintf(sb, ` %s="%s"`, a.Key, html.EscapeString(a.Val))
+	}
+
+	sb.WriteString(">")
+
+	// Void elements have no content or end tag.
+	if slices.Contains([]atom.Atom{atom.Br, atom.Hr, atom.Img}, n.DataAtom) {
+		return
+	}
+
+	p.sanitiseChildren(sb, n)
+	sb.WriteString("</" + n.Data + ">")
+}
+
+// sanitiseChildren writes the sanitised form of each of a node's children to sb.
+func (p *Policy) sanitiseChildren(sb *strings.Builder, n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		p.sanitiseNode(sb, c)
+	}
+}
+
+// allowedAttrs returns the attributes of an element that are kept by the sanitiser, or false if
+// the element itself isn't allowed.
+func (p *Policy) allowedAttrs(n *html.Node) ([]html.Attribute, bool) {
+	switch n.DataAtom {
+	case atom.Img:
+		if p.Images == Strip {
+			return nil, false
+		}
+
+		src := attr(n, "src")
+		if !safeURL(src, false) {
+			return nil, false
+		}
+
+		return filterAttrs(n, "src", "alt", "title", "width", "height"), true
+	case atom.Pre, atom.Code:
+		if p.Code == Plain {
+			return nil, false
+		}
+
+		if class := attr(n, "class"); codeClassRegexp.MatchString(class) {
+			return []html.Attribute{{Key: "class", Val: class}}, true
+		}
+
+		return nil, true
+	case atom.A:
+		attrs := filterAttrs(n, allowedElements[atom.A]...)
+		attrs = slices.DeleteFunc(attrs, func(a html.Attribute) bool { return a.Key == "href" && !safeURL(a.Val, true) })
+
+		if target := p.linkTarget(); target != "" {
+			attrs = append(attrs, html.Attribute{Key: "target", Val: target})
+		}
+
+		if rel := p.linkRel(); rel != "" {
+			attrs = append(attrs, html.Attribute{Key: "rel", Val: rel})
+		}
+
+		return attrs, true
+	}
+
+	allowed, ok := allowedElements[n.DataAtom]
+	if !ok {
+		return nil, false
+	}
+
+	return filterAttrs(n, allowed...), true
+}
+
+// linkTarget returns the target attribute given to links, or an empty string for none.
+func (p *Policy) linkTarget() string {
+	switch p.LinkTarget {
+	case "":
+		return defaultLinkTarget
+	case "none":
+		return ""
+	default:
+		return p.LinkTarget
+	}
+}
+
+// linkRel returns the rel attribute given to links, or an empty string for none.
+func (p *Policy) linkRel() string {
+	switch p.LinkRel {
+	case "":
+		return defaultLinkRel
+	case "none":
+		return ""
+	default:
+		return p.LinkRel
+	}
+}
+
+// attr returns the value of the named attribute of an element, or an empty string.
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key && a.Namespace == "" {
+			return a.Val
+		}
+	}
+
+	return ""
+}
+
+// filterAttrs returns the element's attributes that are in the allowed list.
+func filterAttrs(n *html.Node, allowed ...string) []html.Attribute {
+	attrs := []html.Attribute{}
+
+	for _, a := range n.Attr {
+		if a.Namespace == "" && slices.Contains(allowed, a.Key) {
+			attrs = append(attrs, html.Attribute{Key: a.Key, Val: a.Val})
+		}
+	}
+
+	return attrs
+}
+
+// safeURL reports whether a URL uses a safe scheme. Links may also use mailto URLs, and relative
+// URLs such as fragments.
+func safeURL(raw string, link bool) bool {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || raw == "" {
+		return false
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		return true
+	case "mailto":
+		return link
+	case "":
+		return link && u.Host == "" && !strings.HasPrefix(strings.TrimSpace(raw), "//")
+	default:
+		return false
+	}
+}
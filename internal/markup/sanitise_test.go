@@ -0,0 +1,171 @@
+package markup
+
+import "testing"
+
+func TestSanitise(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   Policy
+		fragment string
+		want     string
+	}{
+		{
+			name:     "allowed elements",
+			fragment: `<p>Some <strong>bold</strong> and <em>emphasised</em> text</p>`,
+			want:     `<p>Some <strong>bold</strong> and <em>emphasised</em> text</p>`,
+		},
+		{
+			name:     "unknown elements keep their content",
+			fragment: `<div><span class="x">text</span></div>`,
+			want:     `text`,
+		},
+		{
+			name:     "script",
+			fragment: `<script>alert(1)</script><p>hi</p>`,
+			want:     `<p>hi</p>`,
+		},
+		{
+			name:     "style",
+			fragment: `<style>p { display: none }</style>hi`,
+			want:     `hi`,
+		},
+		{
+			name:     "svg",
+			fragment: `<svg><script>alert(1)</script><a href="javascript:alert(1)">x</a></svg>hi`,
+			want:     `hi`,
+		},
+		{
+			name:     "math",
+			fragment: `<math><mtext><img src=x onerror=alert(1)></mtext></math>hi`,
+			want:     `hi`,
+		},
+		{
+			name:     "noscript",
+			fragment: `<noscript><p title="</noscript><img src=x onerror=alert(1)>"></noscript>hi`,
+			want:     `&#34;&gt;hi`,
+		},
+		{
+			name:     "textarea",
+			fragment: `<textarea><img src=x onerror=alert(1)></textarea>hi`,
+			want:     `hi`,
+		},
+		{
+			name:     "iframe",
+			fragment: `<iframe src="https://example.com"></iframe>hi`,
+			want:     `hi`,
+		},
+		{
+			name:     "comments",
+			fragment: `<!-- <script>alert(1)</script> -->hi`,
+			want:     `hi`,
+		},
+		{
+			name:     "event handlers",
+			fragment: `<p onclick="alert(1)" onmouseover="alert(1)">hi</p><img src="https://example.com/a.png" onerror="alert(1)">`,
+			want:     `<p>hi</p><img src="https://example.com/a.png">`,
+		},
+		{
+			name:     "style and class attributes",
+			fragment: `<p style="color: red" class="x" id="y">hi</p>`,
+			want:     `<p>hi</p>`,
+		},
+		{
+			name:     "links",
+			fragment: `<a href="https://example.com" title="Example">x</a>`,
+			want:     `<a href="https://example.com" title="Example" target="_blank" rel="noopener noreferrer">x</a>`,
+		},
+		{
+			name:     "relative and mailto links",
+			fragment: `<a href="#usage">x</a><a href="docs/usage.md">y</a><a href="mailto:joe@example.com">z</a>`,
+			want: `<a href="#usage" target="_blank" rel="noopener noreferrer">x</a>` +
+				`<a href="docs/usage.md" target="_blank" rel="noopener noreferrer">y</a>` +
+				`<a href="mailto:joe@example.com" target="_blank" rel="noopener noreferrer">z</a>`,
+		},
+		{
+			name:     "javascript links",
+			fragment: `<a href="javascript:alert(1)">x</a><a href=" JaVaScRiPt:alert(1)">y</a><a href="java&#x09;script:alert(1)">z</a>`,
+			want: `<a target="_blank" rel="noopener noreferrer">x</a>` +
+				`<a target="_blank" rel="noopener noreferrer">y</a>` +
+				`<a target="_blank" rel="noopener noreferrer">z</a>`,
+		},
+		{
+			name:     "data links",
+			fragment: `<a href="data:text/html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==">x</a>`,
+			want:     `<a target="_blank" rel="noopener noreferrer">x</a>`,
+		},
+		{
+			name:     "protocol relative links",
+			fragment: `<a href="//evil.example.com">x</a>`,
+			want:     `<a target="_blank" rel="noopener noreferrer">x</a>`,
+		},
+		{
+			name:     "link target and rel",
+			policy:   Policy{LinkTarget: "none", LinkRel: "nofollow"},
+			fragment: `<a href="https://example.com">x</a>`,
+			want:     `<a href="https://example.com" rel="nofollow">x</a>`,
+		},
+		{
+			name:     "images",
+			fragment: `<img src="https://example.com/a.png" alt="A" class="x">`,
+			want:     `<img src="https://example.com/a.png" alt="A">`,
+		},
+		{
+			name:     "unsafe images",
+			fragment: `<img src="data:image/svg+xml;base64,PHN2Zy8+" alt="A"><img src="//example.com/a.png"><img src="a.png">hi`,
+			want:     `hi`,
+		},
+		{
+			name:     "stripped images",
+			policy:   Policy{Images: Strip},
+			fragment: `<p><img src="https://example.com/a.png" alt="A">hi</p>`,
+			want:     `<p>hi</p>`,
+		},
+		{
+			name:     "code",
+			fragment: `<pre><code class="language-go">x := &lt;-ch</code></pre><code class="evil">y</code>`,
+			want:     `<pre><code class="language-go">x := &lt;-ch</code></pre><code>y</code>`,
+		},
+		{
+			name:     "plain code",
+			policy:   Policy{Code: Plain},
+			fragment: `<pre><code class="language-go">x := &lt;-ch</code></pre><p><code>y</code></p>`,
+			want:     `x := &lt;-ch<p>y</p>`,
+		},
+		{
+			name:     "escaped attributes",
+			fragment: `<a href="https://example.com/?a=1&amp;b=&quot;2&quot;" title="&quot;&gt;<script>">x</a>`,
+			want:     `<a href="https://example.com/?a=1&amp;b=&#34;2&#34;" title="&#34;&gt;&lt;script&gt;" target="_blank" rel="noopener noreferrer">x</a>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Sanitise(tt.fragment); got != tt.want {
+				t.Errorf("Sanitise(%q)\n got %q\nwant %q", tt.fragment, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  Policy
+		wantErr bool
+	}{
+		{"defaults", Policy{}, false},
+		{"options", Policy{LinkTarget: "none", Images: Strip, Code: Plain, Output: OutputHTML}, false},
+		{"unknown link target", Policy{LinkTarget: "_new"}, true},
+		{"unknown images", Policy{Images: Plain}, true},
+		{"unknown code", Policy{Code: Strip}, true},
+		{"unknown output", Policy{Output: "text"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.policy.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+}
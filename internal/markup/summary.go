@@ -0,0 +1,101 @@
+package markup
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// summaryLength is the maximum number of characters in a summary, excluding the ellipsis.
+const summaryLength = 200
+
+// Summary returns a plain text summary of a Markdown body, which is the text of its first
+// paragraph, truncated to a whole word. Headings are skipped, unless the body has nothing else.
+func Summary(body string) string {
+	context := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+
+	nodes, err := html.ParseFragment(strings.NewReader(ToHTML(body)), context)
+	if err != nil {
+		return ""
+	}
+
+	heading := ""
+
+	for _, n := range nodes {
+		text := strings.Join(strings.Fields(textContent(n)), " ")
+		if text == "" {
+			continue
+		}
+
+		if slices.Contains([]atom.Atom{atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6}, n.DataAtom) {
+			if heading == "" {
+				heading = text
+			}
+
+			continue
+		}
+
+		// Lists are summarised by their first item.
+		if n.DataAtom == atom.Ul || n.DataAtom == atom.Ol {
+			text = strings.Join(strings.Fields(textContent(firstElement(n))), " ")
+		}
+
+		return truncate(text)
+	}
+
+	return truncate(heading)
+}
+
+// textContent returns the text of a node and its descendants. The content of the elements that
+// the sanitiser drops, such as scripts and text areas, isn't text that the body displays.
+func textContent(n *html.Node) string {
+	if n == nil {
+		return ""
+	}
+
+	switch {
+	case n.Type == html.TextNode:
+		return n.Data
+	case n.Type == html.ElementNode && slices.Contains(droppedElements, n.DataAtom):
+		return ""
+	}
+
+	var sb strings.Builder
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sb.WriteString(textContent(c))
+	}
+
+	return sb.String()
+}
+
+// firstElement returns the first child of a node that is an element.
+func firstElement(n *html.Node) *html.Node {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// truncate shortens text to at most summaryLength characters, at the end of a word, adding an
+// ellipsis if the text was shortened.
+func truncate(text string) string {
+	if utf8.RuneCountInString(text) <= summaryLength {
+		return text
+	}
+
+	runes := []rune(text)
+	cut := string(runes[:summaryLength])
+
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+
+	return strings.TrimRight(cut, " ,.;:") + "…"
+}
@@ -0,0 +1,36 @@
+package markup
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSummary(t *testing.T) {
+	long := strings.Repeat("word ", 60)
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"empty", "", ""},
+		{"first paragraph", "Some *bold* text.\n\nMore text.", "Some bold text."},
+		{"headings are skipped", "# Release 1.0\n\nSome text.", "Some text."},
+		{"only headings", "# Release 1.0\n\n## Notes", "Release 1.0"},
+		{"first list item", "- Add a thing\n- Fix a thing", "Add a thing"},
+		{"truncated", long, strings.TrimSpace(strings.Repeat("word ", 39)) + " word…"},
+		{"script", "<script>alert(1)</script>hi", "hi"},
+		{"style", "<style>p { display: none }</style>\n\nhi", "hi"},
+		{"textarea", "<textarea><img src=x onerror=alert(1)></textarea>hi", "hi"},
+		{"svg and math", "<svg><text>drawn</text></svg><math><mi>x</mi></math>hi", "hi"},
+		{"comments", "<!-- hidden -->\n\nhi", "hi"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Summary(tt.body); got != tt.want {
+				t.Errorf("Summary(%q) = %q, want %q", tt.body, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package releasegen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jnsgruk/releasegen/internal/credentials"
+	"github.com/jnsgruk/releasegen/internal/github"
+	"github.com/jnsgruk/releasegen/internal/launchpad"
+	"github.com/jnsgruk/releasegen/internal/repos"
+)
+
+// CheckStatus is the outcome of a single online configuration check.
+type CheckStatus string
+
+const (
+	// CheckOK indicates that the configured item exists and is readable.
+	CheckOK CheckStatus = "ok"
+	// CheckWarning indicates a problem that doesn't prevent a report, such as a stale ignore.
+	CheckWarning CheckStatus = "warning"
+	// CheckError indicates a problem that will cause missing data in the report.
+	CheckError CheckStatus = "error"
+)
+
+// CheckFinding is the result of checking a single item in the config against its source.
+type CheckFinding struct {
+	Team    string
+	Source  string
+	Kind    string
+	Name    string
+	Status  CheckStatus
+	Message string
+}
+
+// CheckReport is the list of findings produced by CheckConfig.
+type CheckReport []*CheckFinding
+
+// CheckConfig verifies that the Github orgs, teams and ignored repositories, and the Launchpad
+// project groups referenced in the config exist and are readable.
+func CheckConfig(conf *Config) CheckReport {
+	ctx := context.Background()
+	report := CheckReport{}
+
+	for _, t := range conf.Teams {
+		for i := range t.GithubConfig {
+			report = append(report, checkGithubOrg(ctx, t.Name, &t.GithubConfig[i])...)
+		}
+
+		report = append(report, checkLaunchpad(ctx, t.Name, t.LaunchpadConfig)...)
+	}
+
+	return report
+}
+
+// Failed reports whether any of the findings in the report is an error.
+func (c CheckReport) Failed() bool {
+	return slices.ContainsFunc(c, func(f *CheckFinding) bool { return f.Status == CheckError })
+}
+
+// Print writes the findings in the report to the writer as a table.
+func (c CheckReport) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "TEAM\tSOURCE\tKIND\tNAME\tSTATUS\tMESSAGE")
+
+	for _, f := range c {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", f.Team, f.Source, f.Kind, f.Name, f.Status, f.Message)
+	}
+
+	return tw.Flush()
+}
+
+// checkGithubOrg checks the org, each of its team slugs and each of its ignored repositories.
+func checkGithubOrg(ctx context.Context, team string, org *github.OrgConfig) []*CheckFinding {
+	finding := func(kind, name string, err error) *CheckFinding {
+		f := &CheckFinding{Team: team, Source: "github", Kind: kind, Name: name, Status: CheckOK}
+		if err != nil {
+			f.Status = CheckError
+			f.Message = credentials.Redact(err.Error())
+		}
+
+		return f
+	}
+
+	orgFinding := finding("org", org.Org, org.CheckOrg(ctx))
+	if orgFinding.Status != CheckOK {
+		// There is little value in checking teams and repos for an org that can't be read.
+		return []*CheckFinding{orgFinding}
+	}
+
+	findings := []*CheckFinding{orgFinding}
+
+	for _, slug := range org.Teams {
+		findings = append(findings, finding("team", slug, org.CheckTeam(ctx, slug)))
+	}
+
+	for _, name := range org.Repos {
+		findings = append(findings, finding("repo", name, org.CheckRepo(ctx, name)))
+	}
+
+	for _, ignore := range org.IgnoredRepos {
+		// Patterns may legitimately match nothing, so only plain names are checked.
+		if repos.IsPattern(ignore) {
+			findings = append(findings, &CheckFinding{
+				Team: team, Source: "github", Kind: "ignore", Name: ignore, Status: CheckOK, Message: "pattern not checked",
+			})
+
+			continue
+		}
+
+		// Ignores may refer to a repository by ID, which must be in this org to have any effect.
+		name := ignore
+		if repos.IsID(ignore) {
+			source, owner, repoName, ok := repos.ParseID(ignore)
+			if !ok || source != "github" || !strings.EqualFold(owner, org.Org) {
+				findings = append(findings, &CheckFinding{
+					Team: team, Source: "github", Kind: "ignore", Name: ignore, Status: CheckWarning,
+					Message: "stale ignore: not the id of a repository in this org",
+				})
+
+				continue
+			}
+
+			name = repoName
+		}
+
+		err := org.CheckRepo(ctx, name)
+
+		f := finding("ignore", ignore, err)
+		if errors.Is(err, github.ErrNotFound) {
+			f.Status = CheckWarning
+			f.Message = "stale ignore: repository not found in org"
+		}
+
+		findings = append(findings, f)
+	}
+
+	return findings
+}
+
+// checkLaunchpad checks each of the configured project groups, and that ignored repositories
+// are part of at least one of them.
+func checkLaunchpad(ctx context.Context, team string, conf launchpad.Config) []*CheckFinding {
+	findings := []*CheckFinding{}
+	projects := []string{}
+	resolved := true
+
+	for _, pg := range conf.ProjectGroups {
+		f := &CheckFinding{Team: team, Source: "launchpad", Kind: "project-group", Name: pg, Status: CheckOK}
+
+		pgProjects, err := launchpad.CheckProjectGroup(ctx, pg, conf)
+		if err != nil {
+			f.Status = CheckError
+			f.Message = credentials.Redact(err.Error())
+			resolved = false
+		} else if len(pgProjects) == 0 {
+			f.Status = CheckWarning
+			f.Message = "project group contains no git projects"
+		}
+
+		projects = append(projects, pgProjects...)
+		findings = append(findings, f)
+	}
+
+	// Only flag stale ignores if every project group could be enumerated.
+	if !resolved {
+		return findings
+	}
+
+	for _, ignore := range conf.Ignores() {
+		f := &CheckFinding{Team: team, Source: "launchpad", Kind: "ignore", Name: ignore.String(), Status: CheckOK}
+		matches := func(p string) bool { return ignore.MatchesRepo(repos.ID("launchpad", "", p), p) }
+		if !slices.ContainsFunc(projects, matches) {
+			f.Status = CheckWarning
+			f.Message = "stale ignore: project not found in any project group"
+		}
+
+		findings = append(findings, f)
+	}
+
+	return findings
+}
@@ -1,14 +1,35 @@
 package releasegen
 
 import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/jnsgruk/releasegen/internal/credentials"
 	"github.com/jnsgruk/releasegen/internal/github"
 	"github.com/jnsgruk/releasegen/internal/launchpad"
+	"github.com/jnsgruk/releasegen/internal/markup"
+	"github.com/jnsgruk/releasegen/internal/repos"
 )
 
+// maxUnreleasedCommits is the most unreleased commits that can be listed for each repository,
+// which is the most commits Github returns in a single page.
+const maxUnreleasedCommits = 100
+
 // Config represents the user provided configuration file.
 type Config struct {
-	Teams       []*TeamConfig `yaml:"teams"`
-	githubToken string
+	Teams         []*TeamConfig              `yaml:"teams"`
+	FailurePolicy FailurePolicy              `mapstructure:"failure-policy"`
+	Credentials   map[string]credentials.Ref `mapstructure:"credentials"`
+	Links         markup.Config              `mapstructure:"links"`
+	HTML          markup.Policy              `mapstructure:"html"`
+	Unreleased    UnreleasedConfig           `mapstructure:"unreleased"`
+	Health        repos.Thresholds           `mapstructure:"health"`
+	Sort          string                     `mapstructure:"sort"`
+	Artifacts     repos.ArtifactConfigs      `mapstructure:"artifacts"`
+	githubToken   string
 }
 
 // SetGithubToken enables the setting of the Github token from outside.
@@ -16,9 +37,149 @@ func (c *Config) SetGithubToken(token string) {
 	c.githubToken = token
 }
 
+// Validate checks the parts of the config that can't be checked by parsing alone.
+func (c *Config) Validate() error {
+	err := c.FailurePolicy.Validate()
+	if err != nil {
+		return err
+	}
+
+	if err := c.Links.Validate(); err != nil {
+		return fmt.Errorf("invalid links config: %w", err)
+	}
+
+	if err := c.HTML.Validate(); err != nil {
+		return fmt.Errorf("invalid html config: %w", err)
+	}
+
+	if c.Unreleased.Max() < 0 || c.Unreleased.Max() > maxUnreleasedCommits {
+		return fmt.Errorf("unreleased max-commits must be between 0 and %d", maxUnreleasedCommits)
+	}
+
+	if c.Health.StaleDays < 0 || c.Health.NeedsReleaseDays < 0 || c.Health.NeedsReleaseCommits < 0 {
+		return errors.New("health thresholds must not be negative")
+	}
+
+	if c.Sort != "" && !slices.Contains(repos.SortOrders, c.Sort) {
+		return fmt.Errorf("unknown sort order '%s'", c.Sort)
+	}
+
+	if err := c.Artifacts.Validate(); err != nil {
+		return fmt.Errorf("invalid artifacts config: %w", err)
+	}
+
+	for name, ref := range c.Credentials {
+		if err := ref.Validate(); err != nil {
+			return fmt.Errorf("invalid credential '%s': %w", name, err)
+		}
+	}
+
+	for _, t := range c.Teams {
+		// The configs are validated in place, as validation compiles their patterns.
+		for i := range t.GithubConfig {
+			if err := t.GithubConfig[i].Validate(); err != nil {
+				return err
+			}
+		}
+
+		if err := t.LaunchpadConfig.Validate(); err != nil {
+			return fmt.Errorf("invalid config for team '%s': %w", t.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// NeedsGithubToken reports whether any of the configured Github orgs authenticate using the
+// default Github token, rather than as a Github App or with a credential of their own.
+func (c *Config) NeedsGithubToken() bool {
+	for _, t := range c.Teams {
+		for _, org := range t.GithubConfig {
+			if org.UsesDefaultToken() {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// ResolveCredentials sets the Github token for each Github org, using either the org's own
+// credential, or the default Github token, or the private key of the org's Github App, and the
+// Launchpad OAuth credentials for each team that has them.
+func (c *Config) ResolveCredentials() error {
+	for _, t := range c.Teams {
+		if t.LaunchpadConfig.Credential != nil {
+			secret, err := t.LaunchpadConfig.Credential.Resolve(c.Credentials)
+			if err != nil {
+				return fmt.Errorf("error resolving launchpad credential for team '%s': %w", t.Name, err)
+			}
+
+			if err := t.LaunchpadConfig.SetOAuthCredentials(secret); err != nil {
+				return fmt.Errorf("error resolving launchpad credential for team '%s': %w", t.Name, err)
+			}
+		}
+
+		for i := range t.GithubConfig {
+			org := &t.GithubConfig[i]
+
+			switch {
+			case org.App != nil:
+				key, err := org.App.PrivateKey.Resolve(c.Credentials)
+				if err != nil {
+					return fmt.Errorf("error resolving github app private key for org '%s': %w", org.Org, err)
+				}
+
+				if err := org.App.SetPrivateKey(key); err != nil {
+					return fmt.Errorf("error resolving github app private key for org '%s': %w", org.Org, err)
+				}
+			case org.Credential != nil:
+				token, err := org.Credential.Resolve(c.Credentials)
+				if err != nil {
+					return fmt.Errorf("error resolving credential for github org '%s': %w", org.Org, err)
+				}
+
+				org.SetGithubToken(token)
+			case org.UsesDefaultToken():
+				org.SetGithubToken(c.githubToken)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Hash returns a digest of the teams in the config, which can be used to identify the config
+// that a report was generated from. Credentials are not included in the digest.
+func (c *Config) Hash() string {
+	teams, err := json.Marshal(c.Teams)
+	if err != nil {
+		return ""
+	}
+
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(teams))
+}
+
 // TeamConfig represents the configuration for a given real-life team.
 type TeamConfig struct {
 	Name            string             `mapstructure:"name"`
 	GithubConfig    []github.OrgConfig `mapstructure:"github"`
 	LaunchpadConfig launchpad.Config   `mapstructure:"launchpad"`
 }
+
+// UnreleasedConfig configures the list of commits waiting to be released for each repository.
+// MaxCommits is a pointer, so that zero, which lists none of the commits, can be told apart from
+// the option not being set.
+type UnreleasedConfig struct {
+	MaxCommits *int `mapstructure:"max-commits"`
+}
+
+// Max returns the number of unreleased commits listed for each repository, which defaults to
+// repos.DefaultMaxUnreleased.
+func (u UnreleasedConfig) Max() int {
+	if u.MaxCommits == nil {
+		return repos.DefaultMaxUnreleased
+	}
+
+	return *u.MaxCommits
+}
@@ -0,0 +1,67 @@
+package releasegen
+
+import "github.com/jnsgruk/releasegen/internal/repos"
+
+// TeamMetrics aggregates the release metrics of a team's repositories.
+type TeamMetrics struct {
+	ReleasesLast30Days        int      `json:"releasesLast30Days"`
+	ReleasesLast90Days        int      `json:"releasesLast90Days"`
+	ReleasesLast365Days       int      `json:"releasesLast365Days"`
+	MedianDaysSinceRelease    *float64 `json:"medianDaysSinceRelease"`
+	MedianReleaseIntervalDays *float64 `json:"medianReleaseIntervalDays"`
+	StaleRepos                int      `json:"staleRepos"`
+	NeedsReleaseRepos         int      `json:"needsReleaseRepos"`
+}
+
+// aggregateMetrics sums the release counts and flags of the repositories, and finds the medians
+// of their days since release and release intervals. Release counts that aren't known, because
+// a repository's history was truncated, are left out of the sums.
+func aggregateMetrics(repositories []*repos.RepoDetails) *TeamMetrics {
+	metrics := &TeamMetrics{}
+	sinceRelease, intervals := []float64{}, []float64{}
+
+	for _, r := range repositories {
+		m := r.Metrics
+		if m == nil {
+			continue
+		}
+
+		for _, count := range []struct{ sum, n *int }{
+			{&metrics.ReleasesLast30Days, m.ReleasesLast30Days},
+			{&metrics.ReleasesLast90Days, m.ReleasesLast90Days},
+			{&metrics.ReleasesLast365Days, m.ReleasesLast365Days},
+		} {
+			if count.n != nil {
+				*count.sum += *count.n
+			}
+		}
+
+		if m.DaysSinceRelease != nil {
+			sinceRelease = append(sinceRelease, float64(*m.DaysSinceRelease))
+		}
+
+		if m.MedianReleaseIntervalDays != nil {
+			intervals = append(intervals, *m.MedianReleaseIntervalDays)
+		}
+
+		if m.Stale {
+			metrics.StaleRepos++
+		}
+
+		if m.NeedsRelease {
+			metrics.NeedsReleaseRepos++
+		}
+	}
+
+	if len(sinceRelease) > 0 {
+		median := repos.RoundDays(repos.Median(sinceRelease))
+		metrics.MedianDaysSinceRelease = &median
+	}
+
+	if len(intervals) > 0 {
+		median := repos.RoundDays(repos.Median(intervals))
+		metrics.MedianReleaseIntervalDays = &median
+	}
+
+	return metrics
+}
@@ -0,0 +1,159 @@
+package releasegen
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/jnsgruk/releasegen/internal/github"
+	"github.com/jnsgruk/releasegen/internal/launchpad"
+	"github.com/jnsgruk/releasegen/internal/repos"
+)
+
+// The supported failure policy modes.
+const (
+	// FailFast stops processing at the first org or project group that reports an error.
+	FailFast = "fail-fast"
+	// BestEffort gathers as much data as possible, and only fails if no data could be gathered.
+	BestEffort = "best-effort"
+	// Threshold gathers as much data as possible, and fails if more than a percentage of the
+	// repositories could not be processed.
+	Threshold = "threshold"
+)
+
+var (
+	// ErrAuth is returned when a source rejected the configured credentials.
+	ErrAuth = errors.New("authentication failed")
+	// ErrPartialFailure is returned when some, but not all, of the data could be gathered.
+	ErrPartialFailure = errors.New("report is incomplete")
+	// ErrTotalFailure is returned when errors prevented any data from being gathered.
+	ErrTotalFailure = errors.New("no data could be gathered")
+)
+
+// FailurePolicy configures how releasegen responds to errors while generating a report.
+type FailurePolicy struct {
+	Mode      string  `mapstructure:"mode"`
+	Threshold float64 `mapstructure:"threshold"`
+}
+
+// Validate checks that the failure policy is well formed.
+func (p FailurePolicy) Validate() error {
+	if !slices.Contains([]string{"", FailFast, BestEffort, Threshold}, p.Mode) {
+		return fmt.Errorf("unknown failure policy mode '%s'", p.Mode)
+	}
+
+	if p.Threshold < 0 || p.Threshold > 100 {
+		return errors.New("failure policy threshold must be a percentage between 0 and 100")
+	}
+
+	return nil
+}
+
+// failFast reports whether processing should stop at the first error.
+func (p FailurePolicy) failFast() bool {
+	return p.Mode == FailFast
+}
+
+// Evaluate applies the failure policy to the report, returning ErrAuth, ErrPartialFailure or
+// ErrTotalFailure if the report should be considered failed, or nil otherwise.
+func (r *Report) Evaluate(policy FailurePolicy) error {
+	if err := r.authError(); err != nil {
+		return fmt.Errorf("%w: %w", ErrAuth, err)
+	}
+
+	// Both repositories, and orgs or project groups that couldn't be listed count as failures.
+	// Repositories owned by more than one team are only counted once.
+	total, failed := 0, 0
+	seen := map[*repos.RepoDetails]bool{}
+
+	for _, team := range r.Teams {
+		total += len(team.Errors)
+		failed += len(team.Errors)
+
+		for _, repo := range team.Repos {
+			if seen[repo] {
+				continue
+			}
+
+			seen[repo] = true
+			total++
+
+			if len(repo.Errors) > 0 {
+				failed++
+			}
+		}
+	}
+
+	switch {
+	case failed == 0:
+		return nil
+	case failed == total:
+		return ErrTotalFailure
+	}
+
+	switch policy.Mode {
+	case "", BestEffort:
+		return nil
+	case Threshold:
+		if percentage := float64(failed) / float64(total) * 100; percentage <= policy.Threshold {
+			return nil
+		}
+
+		return fmt.Errorf("%w: %d of %d repositories failed", ErrPartialFailure, failed, total)
+	default:
+		return ErrPartialFailure
+	}
+}
+
+// authErrors maps each source to the error it returns when it rejects the configured credentials.
+//
+//nolint:gochecknoglobals
+var authErrors = map[string]error{"github": github.ErrUnauthorized, "launchpad": launchpad.ErrUnauthorized}
+
+// authError returns the error of a source that rejected the configured credentials, or nil if
+// none did. That's the case if listing an org, team or project group failed with an auth error,
+// or if every repository from the source did. An auth error from only some repositories, such as
+// one enforcing SAML single sign-on, is left to the failure policy.
+func (r *Report) authError() error {
+	errs := slices.Clone(r.errs)
+	sourceRepos := map[string][]*repos.RepoDetails{}
+
+	for _, team := range r.Teams {
+		for _, p := range team.Errors {
+			errs = append(errs, p.Err())
+		}
+
+		for _, repo := range team.Repos {
+			if !slices.Contains(sourceRepos[repo.Source], repo) {
+				sourceRepos[repo.Source] = append(sourceRepos[repo.Source], repo)
+			}
+		}
+	}
+
+	for _, source := range slices.Sorted(maps.Keys(authErrors)) {
+		sentinel := authErrors[source]
+		if slices.ContainsFunc(errs, func(err error) bool { return errors.Is(err, sentinel) }) {
+			return sentinel
+		}
+	}
+
+	for _, source := range slices.Sorted(maps.Keys(sourceRepos)) {
+		if sentinel, ok := authErrors[source]; ok && allRefused(sourceRepos[source], sentinel) {
+			return sentinel
+		}
+	}
+
+	return nil
+}
+
+// allRefused reports whether every one of the repositories recorded the auth error.
+func allRefused(repositories []*repos.RepoDetails, sentinel error) bool {
+	for _, repo := range repositories {
+		if !slices.ContainsFunc(repo.Errors, func(p *repos.Problem) bool { return errors.Is(p.Err(), sentinel) }) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,89 @@
+package releasegen
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jnsgruk/releasegen/internal/github"
+	"github.com/jnsgruk/releasegen/internal/launchpad"
+	"github.com/jnsgruk/releasegen/internal/repos"
+)
+
+func TestReportEvaluate(t *testing.T) {
+	errOther := errors.New("boom")
+	refused := fmt.Errorf("error fetching releases: %w", github.ErrUnauthorized)
+
+	// repo returns a repository from the source that recorded the errors.
+	repo := func(source string, errs ...error) *repos.RepoDetails {
+		r := &repos.RepoDetails{Source: source}
+		for _, err := range errs {
+			r.AddError(source, repos.StageReleases, err)
+		}
+
+		return r
+	}
+
+	tests := []struct {
+		name   string
+		policy FailurePolicy
+		teams  ReleaseReport
+		want   error
+	}{
+		{
+			name:  "no errors",
+			teams: ReleaseReport{{Repos: []*repos.RepoDetails{repo("github"), repo("launchpad")}}},
+		},
+		{
+			name:  "listing refused",
+			teams: ReleaseReport{{Errors: []*repos.Problem{repos.NewProblem("github", repos.StageRepos, refused)}}},
+			want:  ErrAuth,
+		},
+		{
+			name:  "every repo refused",
+			teams: ReleaseReport{{Repos: []*repos.RepoDetails{repo("github", refused), repo("github", refused), repo("launchpad")}}},
+			want:  ErrAuth,
+		},
+		{
+			name: "every launchpad repo refused",
+			teams: ReleaseReport{{Repos: []*repos.RepoDetails{
+				repo("github"), repo("launchpad", launchpad.ErrUnauthorized),
+			}}},
+			want: ErrAuth,
+		},
+		{
+			name:  "some repos refused under best effort",
+			teams: ReleaseReport{{Repos: []*repos.RepoDetails{repo("github", refused), repo("github"), repo("github")}}},
+		},
+		{
+			name:   "some repos refused under threshold",
+			policy: FailurePolicy{Mode: Threshold, Threshold: 10},
+			teams:  ReleaseReport{{Repos: []*repos.RepoDetails{repo("github", refused), repo("github"), repo("github")}}},
+			want:   ErrPartialFailure,
+		},
+		{
+			name:   "within threshold",
+			policy: FailurePolicy{Mode: Threshold, Threshold: 50},
+			teams:  ReleaseReport{{Repos: []*repos.RepoDetails{repo("github", errOther), repo("github"), repo("github")}}},
+		},
+		{
+			name:   "fail fast",
+			policy: FailurePolicy{Mode: FailFast},
+			teams:  ReleaseReport{{Repos: []*repos.RepoDetails{repo("github", errOther), repo("github")}}},
+			want:   ErrPartialFailure,
+		},
+		{
+			name:  "total failure",
+			teams: ReleaseReport{{Repos: []*repos.RepoDetails{repo("github", errOther)}}},
+			want:  ErrTotalFailure,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := (&Report{Teams: tt.teams}).Evaluate(tt.policy); !errors.Is(err, tt.want) {
+				t.Errorf("Evaluate() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -5,46 +5,175 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/jnsgruk/releasegen/internal/repos"
 )
 
+// SchemaVersion is the version of the JSON Schema describing the report envelope, found in
+// the schema directory of the repository. It is incremented on incompatible changes.
+const SchemaVersion = 2
+
+// runSource identifies releasegen itself as the source of run level problems.
+const runSource = "releasegen"
+
 // ReleaseReport is a representation of the output of releasegen.
 type ReleaseReport []*TeamDetails
 
-// GenerateReport takes a given config, and generates the output JSON.
-func GenerateReport(conf *Config) ReleaseReport {
-	teams := ReleaseReport{}
+// Report holds the teams gathered by a run of releasegen, along with metadata about the run.
+type Report struct {
+	Teams       ReleaseReport
+	GeneratedAt time.Time
+	Duration    time.Duration
+	ConfigHash  string
+	Stats       map[string]*SourceStats
+	// Errors are problems with the run as a whole, rather than a single team, such as a config
+	// that couldn't be loaded, or processing that stopped early.
+	Errors []*repos.Problem
+
+	// errs holds the errors returned while processing each team.
+	errs []error
+}
+
+// BuildInfo describes the build of releasegen that generated a report.
+type BuildInfo struct {
+	Version string `json:"version"`
+	Commit  string `json:"commit"`
+	Date    string `json:"date"`
+}
+
+// Envelope is the serialisable form of a Report, which wraps the teams with metadata.
+type Envelope struct {
+	SchemaVersion int                     `json:"schemaVersion"`
+	GeneratedAt   time.Time               `json:"generatedAt"`
+	Duration      float64                 `json:"duration"`
+	Generator     BuildInfo               `json:"generator"`
+	ConfigHash    string                  `json:"configHash"`
+	Stats         map[string]*SourceStats `json:"stats"`
+	Errors        []*repos.Problem        `json:"errors"`
+	Teams         ReleaseReport           `json:"teams"`
+}
+
+// SourceStats summarises the data gathered from a single source, such as Github or Launchpad.
+type SourceStats struct {
+	Repos    int `json:"repos"`
+	Releases int `json:"releases"`
+	Tags     int `json:"tags"`
+	Commits  int `json:"commits"`
+	Errors   int `json:"errors"`
+	Warnings int `json:"warnings"`
+}
+
+// GenerateReport takes a given config, and generates the output JSON. Under the fail-fast failure
+// policy, the report is returned as soon as a team encounters an error.
+func GenerateReport(conf *Config) *Report {
+	report := &Report{
+		Teams:       ReleaseReport{},
+		GeneratedAt: time.Now().UTC(),
+		ConfigHash:  conf.Hash(),
+		Stats:       map[string]*SourceStats{},
+	}
+
+	// Repositories are shared between the teams that own them, so that each is fetched once.
+	index := repos.NewIndex()
+	counted := map[*repos.RepoDetails]bool{}
 
 	// Iterate over the teams specified in the config file.
 	for _, t := range conf.Teams {
 		team := &Team{
 			Details: &TeamDetails{
 				Name:  t.Name,
-				Repos: []repos.RepoDetails{},
+				Repos: []*repos.RepoDetails{},
 			},
-			config:      *t,
-			githubToken: conf.githubToken,
+			config:  *t,
+			policy:  conf.FailurePolicy,
+			stats:   report.Stats,
+			links:   &conf.Links,
+			html:    &conf.HTML,
+			index:   index,
+			counted: counted,
+
+			maxUnreleased: conf.Unreleased.Max(),
+			now:           report.GeneratedAt,
+			health:        conf.Health,
+			sort:          conf.Sort,
+			artifacts:     conf.Artifacts,
 		}
-		teams = append(teams, team.Details)
+		report.Teams = append(report.Teams, team.Details)
 
 		err := team.Process()
 		if err != nil {
 			log.Printf("error processing team '%s': %v", team.Details.Name, err)
+			report.errs = append(report.errs, err)
+
+			if conf.FailurePolicy.failFast() {
+				report.Errors = append(report.Errors, repos.NewProblem(runSource, repos.StageRepos,
+					fmt.Errorf("stopped after errors processing team '%s' under the fail-fast policy", team.Details.Name)))
+
+				break
+			}
 		}
 	}
 
-	return teams
+	report.Duration = time.Since(report.GeneratedAt)
+
+	return report
+}
+
+// FailedReport returns a report with no teams, recording the error that prevented the run at the
+// given stage, such as StageConfig. It is output so that consumers of the report can tell that
+// the run failed, rather than finding the report missing.
+func FailedReport(stage string, err error) *Report {
+	return &Report{
+		Teams:       ReleaseReport{},
+		GeneratedAt: time.Now().UTC(),
+		Stats:       map[string]*SourceStats{},
+		Errors:      []*repos.Problem{repos.NewProblem(runSource, stage, err)},
+	}
+}
+
+// Envelope wraps the report's teams with metadata about the run, and the build of releasegen.
+func (r *Report) Envelope(build BuildInfo) *Envelope {
+	return &Envelope{
+		SchemaVersion: SchemaVersion,
+		GeneratedAt:   r.GeneratedAt,
+		Duration:      r.Duration.Seconds(),
+		Generator:     build,
+		ConfigHash:    r.ConfigHash,
+		Stats:         r.Stats,
+		Errors:        r.Errors,
+		Teams:         r.Teams,
+	}
 }
 
 // Dump is used to create a pretty-printed JSON version of a ReleaseReport.
 func (r ReleaseReport) Dump() {
+	dumpJSON(r)
+}
+
+// Dump is used to create a pretty-printed JSON version of an Envelope.
+func (e *Envelope) Dump() {
+	dumpJSON(e)
+}
+
+// add updates the statistics with the data gathered for the given repository.
+func (s *SourceStats) add(r *repos.RepoDetails) {
+	s.Repos++
+	s.Releases += len(r.Releases)
+	s.Tags += len(r.Tags)
+	s.Commits += len(r.Commits)
+	s.Errors += len(r.Errors)
+	s.Warnings += len(r.Warnings)
+}
+
+// dumpJSON prints a pretty-printed JSON representation of v to stdout.
+func dumpJSON(v any) {
 	buffer := &bytes.Buffer{}
 	encoder := json.NewEncoder(buffer)
 	encoder.SetEscapeHTML(false)
 	encoder.SetIndent("", "   ")
 
-	if err := encoder.Encode(r); err != nil {
+	if err := encoder.Encode(v); err != nil {
 		log.Fatalln("unable to encode report to JSON")
 	}
 
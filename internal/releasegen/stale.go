@@ -0,0 +1,253 @@
+package releasegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jnsgruk/releasegen/internal/repos"
+	"github.com/jnsgruk/releasegen/internal/stores"
+)
+
+// The checks made by FindStale.
+const (
+	// StaleUnreleased flags repositories with unreleased commits older than a number of days.
+	StaleUnreleased = "unreleased"
+	// StaleNoRelease flags repositories that haven't been released or tagged for a number of
+	// months.
+	StaleNoRelease = "no-release"
+	// StaleSnapLag flags snaps whose stable channel lags the edge channel of the same track.
+	StaleSnapLag = "snap-lag"
+	// StaleCharmNoStable flags charms that haven't been released to a stable channel.
+	StaleCharmNoStable = "charm-no-stable"
+)
+
+var (
+	// ErrStale is returned when the number of stale findings exceeds the allowed maximum.
+	ErrStale = errors.New("too many stale repositories")
+	// errSchemaVersion is returned when reading a report with an unsupported schema version.
+	errSchemaVersion = errors.New("unsupported report")
+)
+
+// StaleOptions configures the checks made by FindStale. A threshold of zero disables its check.
+type StaleOptions struct {
+	// UnreleasedDays is the age in days after which unreleased commits are stale.
+	UnreleasedDays int
+	// ReleaseMonths is the number of months without a release after which a repository is stale.
+	ReleaseMonths int
+	// LagRevisions is the number of revisions a snap's stable channel may lag its edge channel.
+	LagRevisions int
+	// LagDays is the number of days a snap's stable channel may lag its edge channel.
+	LagDays int
+}
+
+// StaleFinding is a single problem found by FindStale.
+type StaleFinding struct {
+	ID     string   `json:"id"`
+	Teams  []string `json:"teams"`
+	Check  string   `json:"check"`
+	Detail string   `json:"detail"`
+}
+
+// StaleReport is the list of findings produced by FindStale.
+type StaleReport []*StaleFinding
+
+// ReadReport reads a report previously output by releasegen, either with or without its
+// envelope. The generation time of a report without an envelope isn't known, so it's zero. A
+// report without an envelope has no schema version, so it's rejected if its repositories have
+// the 'snap' or 'charm' fields of version 1, which would otherwise be silently dropped.
+func ReadReport(r io.Reader) (*Report, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("error reading report: %w", err)
+	}
+
+	report := &Report{}
+
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		if isV1Report(data) {
+			return nil, fmt.Errorf("%w: report without an envelope has the 'snap' and 'charm' fields of "+
+				"schema version 1, expected %d", errSchemaVersion, SchemaVersion)
+		}
+
+		err = json.Unmarshal(data, &report.Teams)
+	} else {
+		envelope := &Envelope{}
+		err = json.Unmarshal(data, envelope)
+		report.Teams, report.GeneratedAt = envelope.Teams, envelope.GeneratedAt
+
+		if err == nil && envelope.SchemaVersion != SchemaVersion {
+			return nil, fmt.Errorf("%w: report has schema version %d, expected %d",
+				errSchemaVersion, envelope.SchemaVersion, SchemaVersion)
+		}
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error parsing report: %w", err)
+	}
+
+	return report, nil
+}
+
+// isV1Report reports whether any repository in a report without an envelope has the 'snap' or
+// 'charm' fields, which were replaced by 'snaps' and 'charms' in schema version 2.
+func isV1Report(data []byte) bool {
+	var teams []struct {
+		Repos []struct {
+			Snap  json.RawMessage `json:"snap"`
+			Charm json.RawMessage `json:"charm"`
+		} `json:"repos"`
+	}
+
+	if json.Unmarshal(data, &teams) != nil {
+		return false
+	}
+
+	for _, team := range teams {
+		for _, r := range team.Repos {
+			if r.Snap != nil || r.Charm != nil {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// FindStale checks each repository in the report for unreleased commits that are too old, a
+// release that is too old, snaps whose stable channel lags their edge channel, and charms with no
+// stable channel. Ages are measured from the given time. Repositories owned by several teams are
+// only checked once.
+func FindStale(teams ReleaseReport, opts StaleOptions, now time.Time) StaleReport {
+	report := StaleReport{}
+	seen := map[string]bool{}
+
+	for _, team := range teams {
+		for _, r := range team.Repos {
+			if seen[r.ID] {
+				continue
+			}
+
+			seen[r.ID] = true
+
+			finding := func(check, detail string, args ...any) {
+				report = append(report, &StaleFinding{
+					ID:     r.ID,
+					Teams:  r.Owners,
+					Check:  check,
+					Detail: fmt.Sprintf(detail, args...),
+				})
+			}
+
+			if age, ok := unreleasedAge(r, now); ok && opts.UnreleasedDays > 0 && age > opts.UnreleasedDays {
+				finding(StaleUnreleased, "%d unreleased commits, the oldest %d days old", r.NewCommits, age)
+			}
+
+			if opts.ReleaseMonths > 0 {
+				latest := r.LatestVersion()
+				cutoff := now.AddDate(0, -opts.ReleaseMonths, 0)
+
+				switch {
+				case latest == 0:
+					finding(StaleNoRelease, "never released or tagged")
+				case time.Unix(latest, 0).Before(cutoff):
+					finding(StaleNoRelease, "last released %s", time.Unix(latest, 0).UTC().Format(time.DateOnly))
+				}
+			}
+
+			for _, snap := range r.Snaps {
+				for _, detail := range snapLag(snap, opts) {
+					finding(StaleSnapLag, "%s", detail)
+				}
+			}
+
+			for _, charm := range r.Charms {
+				if !slices.Contains(charm.Channels, "stable") {
+					finding(StaleCharmNoStable, "%s has no stable channel", charm.Name)
+				}
+			}
+		}
+	}
+
+	return report
+}
+
+// Print writes the findings in the report to the writer as a table.
+func (s StaleReport) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "REPO\tTEAMS\tCHECK\tDETAIL")
+
+	for _, f := range s {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", f.ID, strings.Join(f.Teams, ","), f.Check, f.Detail)
+	}
+
+	return tw.Flush()
+}
+
+// JSON writes the findings in the report to the writer as pretty-printed JSON.
+func (s StaleReport) JSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "   ")
+
+	return encoder.Encode(s)
+}
+
+// unreleasedAge returns the age in days of a repository's oldest unreleased commit, which is
+// taken from its metrics, or from the unreleased commits listed if it has none.
+func unreleasedAge(r *repos.RepoDetails, now time.Time) (int, bool) {
+	if r.Metrics != nil && r.Metrics.UnreleasedAgeDays != nil {
+		return *r.Metrics.UnreleasedAgeDays, true
+	}
+
+	if len(r.Unreleased) == 0 {
+		return 0, false
+	}
+
+	oldest := r.Unreleased[len(r.Unreleased)-1].Timestamp
+
+	return int(now.Sub(time.Unix(oldest, 0)) / repos.Day), true
+}
+
+// snapLag describes each track of a snap in which the stable channel lags the edge channel by
+// more revisions or days than allowed. The newest revision in each channel is compared, because
+// the store lists a release for each architecture.
+func snapLag(snap *stores.Artifact, opts StaleOptions) []string {
+	newest := func(track, channel string) *stores.Release {
+		var found *stores.Release
+
+		for _, rel := range snap.Releases {
+			if rel.Track == track && rel.Channel == channel && (found == nil || rel.Revision > found.Revision) {
+				found = rel
+			}
+		}
+
+		return found
+	}
+
+	details := []string{}
+
+	for _, track := range snap.Tracks {
+		stable, edge := newest(track, "stable"), newest(track, "edge")
+		if stable == nil || edge == nil || edge.Revision <= stable.Revision {
+			continue
+		}
+
+		revisions := edge.Revision - stable.Revision
+		days := int(time.Unix(edge.Timestamp, 0).Sub(time.Unix(stable.Timestamp, 0)) / repos.Day)
+
+		if (opts.LagRevisions > 0 && revisions > int64(opts.LagRevisions)) || (opts.LagDays > 0 && days > opts.LagDays) {
+			details = append(details, fmt.Sprintf("%s %s/stable is %d revisions and %d days behind %s/edge",
+				snap.Name, track, revisions, days, track))
+		}
+	}
+
+	return details
+}
@@ -1,67 +1,157 @@
 package releasegen
 
 import (
+	"errors"
 	"fmt"
 	"log"
-	"sort"
+	"slices"
+	"time"
 
 	"github.com/jnsgruk/releasegen/internal/github"
 	"github.com/jnsgruk/releasegen/internal/launchpad"
+	"github.com/jnsgruk/releasegen/internal/markup"
 	"github.com/jnsgruk/releasegen/internal/repos"
 )
 
 // TeamDetails is the serialisable form of a real-life team.
 type TeamDetails struct {
-	Name  string              `json:"team"`
-	Repos []repos.RepoDetails `json:"repos"`
+	Name    string               `json:"team"`
+	Repos   []*repos.RepoDetails `json:"repos"`
+	Metrics *TeamMetrics         `json:"metrics"`
+	Errors  []*repos.Problem     `json:"errors"`
 }
 
+// errRepoFailures is returned under the fail-fast policy when repositories failed to process.
+var errRepoFailures = errors.New("stopping after errors processing repositories")
+
 // Team represents a given "real-life Team".
 type Team struct {
-	Details     *TeamDetails
-	config      TeamConfig
-	githubToken string
+	Details *TeamDetails
+	config  TeamConfig
+	policy  FailurePolicy
+	stats   map[string]*SourceStats
+	links   *markup.Config
+	html    *markup.Policy
+	index   *repos.Index
+	counted map[*repos.RepoDetails]bool
+
+	// maxUnreleased is the maximum number of unreleased commits listed for each repository.
+	maxUnreleased int
+	// now is the time that release metrics are calculated at, and health sets the thresholds
+	// for flagging repositories as stale or needing a release.
+	now    time.Time
+	health repos.Thresholds
+	// sort is the order that the team's repositories are listed in.
+	sort string
+	// artifacts are the store artifacts declared for repositories in the config.
+	artifacts repos.ArtifactConfigs
 }
 
-// Process populates a given team with the details of its Github/Launchpad repos.
+// Process populates a given team with the details of its Github/Launchpad repos. Unless the
+// team's failure policy is fail-fast, a failure in one org or project group doesn't prevent the
+// rest from being processed, and all of the errors encountered are returned.
 func (t *Team) Process() error {
 	log.Printf("processing team: %s", t.config.Name)
 
-	// Iterate over the Github orgs for a given team.
-	for _, org := range t.config.GithubConfig {
+	errs := []error{}
+
+	// Iterate over the Github orgs for a given team. Each org is used in place, so that its
+	// client, and any Github App installation token, is reused rather than created again.
+	for i := range t.config.GithubConfig {
+		org := &t.config.GithubConfig[i]
 		log.Printf("processing github org: %s\n", org.Org)
 
-		// Set the Github token on the org so it can access the API
-		org.SetGithubToken(t.githubToken)
+		org.SetRenderer(t.renderer("github"))
+		org.SetMaxUnreleased(t.maxUnreleased)
+		org.SetArtifacts(t.artifacts)
 
-		ghRepos, err := github.FetchOrgRepos(org)
+		ghRepos, err := github.FetchOrgRepos(org, t.index, t.config.Name)
 		if err != nil {
-			return fmt.Errorf("error populating github repos: %w", err)
+			t.addError("github", err)
+			errs = append(errs, fmt.Errorf("error populating github repos: %w", err))
 		}
 
-		t.Details.Repos = append(t.Details.Repos, ghRepos...)
+		t.addRepos("github", ghRepos)
+
+		if t.policy.failFast() && (err != nil || hasRepoErrors(ghRepos)) {
+			return errors.Join(append(errs, fmt.Errorf("%w: github org '%s'", errRepoFailures, org.Org))...)
+		}
 	}
 
+	lpConfig := t.config.LaunchpadConfig
+	lpConfig.SetRenderer(t.renderer("launchpad"))
+	lpConfig.SetMaxUnreleased(t.maxUnreleased)
+	lpConfig.SetArtifacts(t.artifacts)
+
 	// Iterate over the Launchpad Project Groups for the team.
-	for _, group := range t.config.LaunchpadConfig.ProjectGroups {
+	for _, group := range lpConfig.ProjectGroups {
 		log.Printf("processing launchpad project group: %s\n", group)
 
-		lpRepos, err := launchpad.FetchProjectGroupRepos(group, t.config.LaunchpadConfig)
+		lpRepos, err := launchpad.FetchProjectGroupRepos(group, lpConfig, t.index, t.config.Name)
 		if err != nil {
-			return fmt.Errorf("error populating launchpad repos: %w", err)
+			t.addError("launchpad", err)
+			errs = append(errs, fmt.Errorf("error populating launchpad repos: %w", err))
 		}
 
-		t.Details.Repos = append(t.Details.Repos, lpRepos...)
+		t.addRepos("launchpad", lpRepos)
+
+		if t.policy.failFast() && (err != nil || hasRepoErrors(lpRepos)) {
+			return errors.Join(append(errs, fmt.Errorf("%w: launchpad project group '%s'", errRepoFailures, group))...)
+		}
 	}
 
-	// Sort the repos by the last released.
-	sort.Slice(t.Details.Repos, func(i, j int) bool {
-		if len(t.Details.Repos[i].Releases) == 0 || len(t.Details.Repos[j].Releases) == 0 {
-			return false
+	repos.Sort(t.Details.Repos, t.sort)
+
+	t.Details.Metrics = aggregateMetrics(t.Details.Repos)
+
+	return errors.Join(errs...)
+}
+
+// renderer returns the Renderer for the named source's release bodies and commit messages.
+func (t *Team) renderer(source string) *markup.Renderer {
+	return markup.NewRenderer(t.links.Linker(source), t.html)
+}
+
+// addRepos adds the repositories to the team, skipping any that the team already has. Each
+// repository is only counted in the statistics once, however many teams own it.
+func (t *Team) addRepos(source string, repositories []*repos.RepoDetails) {
+	for _, r := range repositories {
+		if slices.Contains(t.Details.Repos, r) {
+			continue
 		}
 
-		return t.Details.Repos[i].Releases[0].Timestamp > t.Details.Repos[j].Releases[0].Timestamp
-	})
+		t.Details.Repos = append(t.Details.Repos, r)
+
+		if t.counted[r] {
+			continue
+		}
+
+		t.counted[r] = true
+		t.sourceStats(source).add(r)
+		r.ComputeMetrics(t.now, t.health)
+	}
+}
+
+// addError records a team level error encountered while listing repositories from a source.
+func (t *Team) addError(source string, err error) {
+	t.Details.Errors = append(t.Details.Errors, repos.NewProblem(source, repos.StageRepos, err))
+	t.sourceStats(source).Errors++
+}
+
+// sourceStats returns the statistics for the named source, creating them if necessary.
+func (t *Team) sourceStats(source string) *SourceStats {
+	if t.stats == nil {
+		t.stats = map[string]*SourceStats{}
+	}
+
+	if _, ok := t.stats[source]; !ok {
+		t.stats[source] = &SourceStats{}
+	}
+
+	return t.stats[source]
+}
 
-	return nil
+// hasRepoErrors reports whether any of the repositories recorded errors while being processed.
+func hasRepoErrors(repositories []*repos.RepoDetails) bool {
+	return slices.ContainsFunc(repositories, func(r *repos.RepoDetails) bool { return len(r.Errors) > 0 })
 }
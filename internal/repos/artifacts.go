@@ -0,0 +1,79 @@
+package repos
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jnsgruk/releasegen/internal/stores"
+)
+
+// ArtifactConfig contains fields used in releasegen's config.yaml file to declare the store
+// artifacts and registry packages published from a repository, which is identified by its ID.
+type ArtifactConfig struct {
+	Repo   string   `mapstructure:"repo"`
+	Snaps  []string `mapstructure:"snaps"`
+	Charms []string `mapstructure:"charms"`
+	PyPI   []string `mapstructure:"pypi"`
+	Crates []string `mapstructure:"crates"`
+	NPM    []string `mapstructure:"npm"`
+	Go     []string `mapstructure:"go"`
+}
+
+// Packages returns the names of the packages declared for the named registry.
+func (c *ArtifactConfig) Packages(registry string) []string {
+	switch registry {
+	case stores.RegistryPyPI:
+		return c.PyPI
+	case stores.RegistryCrates:
+		return c.Crates
+	case stores.RegistryNPM:
+		return c.NPM
+	case stores.RegistryGo:
+		return c.Go
+	default:
+		return nil
+	}
+}
+
+// ArtifactConfigs is the list of repositories with declared artifacts.
+type ArtifactConfigs []*ArtifactConfig
+
+// Validate checks that each repository is identified by its ID, and is only declared once.
+func (a ArtifactConfigs) Validate() error {
+	seen := map[string]bool{}
+
+	for _, c := range a {
+		if _, _, _, ok := ParseID(c.Repo); !ok {
+			return fmt.Errorf("artifacts repo '%s' is not a repository id, such as 'github:acme/tools'", c.Repo)
+		}
+
+		if seen[strings.ToLower(c.Repo)] {
+			return fmt.Errorf("artifacts for repo '%s' are declared more than once", c.Repo)
+		}
+
+		seen[strings.ToLower(c.Repo)] = true
+
+		for _, names := range [][]string{c.Snaps, c.Charms, c.PyPI, c.Crates, c.NPM, c.Go} {
+			for _, name := range names {
+				if name == "" {
+					return errors.New("artifact names must not be empty")
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// For returns the artifacts declared for the repository with the given ID, or nil if there are
+// none. IDs are compared case-insensitively, since Github owner and repository names are.
+func (a ArtifactConfigs) For(id string) *ArtifactConfig {
+	for _, c := range a {
+		if strings.EqualFold(c.Repo, id) {
+			return c
+		}
+	}
+
+	return nil
+}
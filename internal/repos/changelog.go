@@ -0,0 +1,204 @@
+package repos
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// The sections of a changelog that entries are sorted into.
+const (
+	sectionNone     = ""
+	sectionFeatures = "features"
+	sectionFixes    = "fixes"
+	sectionBreaking = "breaking"
+)
+
+var (
+	// headingRegexp is used to find Markdown headings, and lines that are entirely bold, which are
+	// often used as headings in release notes.
+	headingRegexp = regexp.MustCompile(`^\s*(?:#{1,6}\s+(.+?)\s*#*|\*\*([^*]+)\*\*:?)\s*$`)
+	// bulletRegexp is used to find the items of Markdown lists.
+	bulletRegexp = regexp.MustCompile(`^\s*(?:[-*+]|[0-9]+[.)])\s+(.+)$`)
+	// conventionalRegexp is used to find Conventional Commit style entries such as
+	// 'feat(api)!: add a thing'.
+	conventionalRegexp = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?:\s+(.+)$`)
+	// breakingRegexp is used to find 'BREAKING CHANGE: ...' footers.
+	breakingRegexp = regexp.MustCompile(`^\s*BREAKING[ -]CHANGES?:\s+(.+)$`)
+	// generatedSuffixRegexp is used to find the suffix Github adds to generated release notes,
+	// such as ' by @JoeBloggs in https://github.com/acme/tools/pull/34'.
+	generatedSuffixRegexp = regexp.MustCompile(`\s+by\s+@[\w-]+(?:\s+in\s+\S+)?\s*$`)
+	// mentionRegexp is used to find mentions of users such as '@JoeBloggs'.
+	mentionRegexp = regexp.MustCompile(`(?:^|[\s(\[])@([\w-]+)`)
+	// pullRefRegexp is used to find references to pull requests such as '#34'.
+	pullRefRegexp = regexp.MustCompile(`(?:^|[^\w&/#])#([0-9]+)\b`)
+	// pullURLRegexp is used to find the numbers in pull request URLs.
+	pullURLRegexp = regexp.MustCompile(`(https?://\S+?)/pull/([0-9]+)\b`)
+	// mergeSubjectRegexp is used to find the pull request merged by a commit, from subjects such
+	// as 'Merge pull request #34 from acme/branch' or 'Add a thing (#34)'.
+	mergeSubjectRegexp = regexp.MustCompile(`^Merge pull request #([0-9]+)\b|\(#([0-9]+)\)\s*$`)
+)
+
+// conventionalTypes maps the known Conventional Commit types to the section of the changelog
+// their entries belong in. Other types, such as 'chore' or 'docs', are only of interest if
+// they're breaking.
+//
+//nolint:gochecknoglobals
+var conventionalTypes = map[string]string{
+	"feat": sectionFeatures, "feature": sectionFeatures, "fix": sectionFixes, "bugfix": sectionFixes,
+	"build": sectionNone, "chore": sectionNone, "ci": sectionNone, "docs": sectionNone,
+	"perf": sectionNone, "refactor": sectionNone, "revert": sectionNone, "style": sectionNone,
+	"test": sectionNone,
+}
+
+// Changelog is the structured form of a release's notes.
+type Changelog struct {
+	Features        []*ChangelogEntry `json:"features"`
+	Fixes           []*ChangelogEntry `json:"fixes"`
+	BreakingChanges []*ChangelogEntry `json:"breakingChanges"`
+	Contributors    []string          `json:"contributors"`
+	PullRequests    []int             `json:"pullRequests"`
+}
+
+// ChangelogEntry is a single change listed in a release's notes.
+type ChangelogEntry struct {
+	Text  string `json:"text"`
+	Scope string `json:"scope"`
+}
+
+// ParseChangelog parses the Markdown notes of a release into a Changelog. Entries are sorted by
+// the heading they're listed under, such as '## Bug fixes', or by their Conventional Commit
+// type, such as 'feat: ...'. Pull requests are only counted if they're in the repository at
+// repoURL.
+func ParseChangelog(body, repoURL string) *Changelog {
+	changelog := &Changelog{
+		Features:        []*ChangelogEntry{},
+		Fixes:           []*ChangelogEntry{},
+		BreakingChanges: []*ChangelogEntry{},
+		Contributors:    []string{},
+		PullRequests:    []int{},
+	}
+
+	section := sectionNone
+	inCode := false
+
+	for _, line := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n") {
+		// Skip the contents of code blocks.
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+			continue
+		}
+
+		if inCode {
+			continue
+		}
+
+		changelog.addReferences(line, repoURL)
+
+		if m := headingRegexp.FindStringSubmatch(line); m != nil {
+			section = headingSection(m[1] + m[2])
+			continue
+		}
+
+		if m := breakingRegexp.FindStringSubmatch(line); m != nil {
+			changelog.BreakingChanges = append(changelog.BreakingChanges, &ChangelogEntry{Text: m[1]})
+			continue
+		}
+
+		if m := bulletRegexp.FindStringSubmatch(line); m != nil {
+			changelog.addEntry(section, m[1])
+		}
+	}
+
+	return changelog
+}
+
+// addEntry adds a list item to the section of the changelog it belongs in. Items with a known
+// Conventional Commit type are sorted by their type, and all others, including those with a
+// prefix such as 'API: ...', are sorted by the heading they're listed under.
+func (c *Changelog) addEntry(section, item string) {
+	entry := &ChangelogEntry{Text: strings.TrimSpace(generatedSuffixRegexp.ReplaceAllString(item, ""))}
+	breaking := section == sectionBreaking
+
+	if m := conventionalRegexp.FindStringSubmatch(entry.Text); m != nil {
+		if typeSection, ok := conventionalTypes[strings.ToLower(m[1])]; ok {
+			entry.Text, entry.Scope = m[4], m[2]
+			breaking = breaking || m[3] == "!"
+			section = typeSection
+		}
+	}
+
+	if breaking {
+		c.BreakingChanges = append(c.BreakingChanges, entry)
+	}
+
+	switch section {
+	case sectionFeatures:
+		c.Features = append(c.Features, entry)
+	case sectionFixes:
+		c.Fixes = append(c.Fixes, entry)
+	}
+}
+
+// addReferences adds the users mentioned, and pull requests referred to, in a line of the notes.
+func (c *Changelog) addReferences(line, repoURL string) {
+	for _, m := range mentionRegexp.FindAllStringSubmatch(line, -1) {
+		if !slices.Contains(c.Contributors, m[1]) {
+			c.Contributors = append(c.Contributors, m[1])
+		}
+	}
+
+	numbers := []string{}
+
+	for _, m := range pullURLRegexp.FindAllStringSubmatch(line, -1) {
+		if repoURL != "" && strings.EqualFold(m[1], repoURL) {
+			numbers = append(numbers, m[2])
+		}
+	}
+
+	// URLs have been counted already, and must not also be counted as '#<number>' references.
+	for _, m := range pullRefRegexp.FindAllStringSubmatch(pullURLRegexp.ReplaceAllString(line, ""), -1) {
+		numbers = append(numbers, m[1])
+	}
+
+	for _, n := range numbers {
+		number, err := strconv.Atoi(n)
+		if err == nil && !slices.Contains(c.PullRequests, number) {
+			c.PullRequests = append(c.PullRequests, number)
+		}
+	}
+}
+
+// headingSection returns the section of the changelog that entries under a heading belong in.
+func headingSection(heading string) string {
+	heading = strings.ToLower(heading)
+
+	switch {
+	case strings.Contains(heading, "contributor"):
+		// Such as Github's 'New Contributors' section, which lists people, not changes.
+		return sectionNone
+	case strings.Contains(heading, "breaking"):
+		return sectionBreaking
+	case strings.Contains(heading, "fix"), strings.Contains(heading, "bug"):
+		return sectionFixes
+	case strings.Contains(heading, "feature"), strings.Contains(heading, "enhancement"),
+		strings.Contains(heading, "added"), heading == "new" || strings.HasPrefix(heading, "new "):
+		return sectionFeatures
+	default:
+		return sectionNone
+	}
+}
+
+// MergedPullRequest returns the number of the pull request merged by a commit with the given
+// subject, which Github adds to merge and squash commits, or zero if there isn't one.
+func MergedPullRequest(subject string) int {
+	m := mergeSubjectRegexp.FindStringSubmatch(subject)
+	if m == nil {
+		return 0
+	}
+
+	number, _ := strconv.Atoi(m[1] + m[2])
+
+	return number
+}
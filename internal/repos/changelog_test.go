@@ -0,0 +1,175 @@
+package repos
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestParseChangelog(t *testing.T) {
+	const repoURL = "https://github.com/acme/tools"
+
+	tests := []struct {
+		name string
+		body string
+		want *Changelog
+	}{
+		{
+			name: "empty",
+			body: "",
+			want: &Changelog{},
+		},
+		{
+			name: "headings",
+			body: "## Features\n- Add a thing\n\n## Bug fixes\n* Fix a thing\n\n## Breaking changes\n- Remove a thing",
+			want: &Changelog{
+				Features:        []*ChangelogEntry{{Text: "Add a thing"}},
+				Fixes:           []*ChangelogEntry{{Text: "Fix a thing"}},
+				BreakingChanges: []*ChangelogEntry{{Text: "Remove a thing"}},
+			},
+		},
+		{
+			name: "bold headings",
+			body: "**New features:**\n- Add a thing\n\n**Bugs**\n1. Fix a thing",
+			want: &Changelog{
+				Features: []*ChangelogEntry{{Text: "Add a thing"}},
+				Fixes:    []*ChangelogEntry{{Text: "Fix a thing"}},
+			},
+		},
+		{
+			name: "conventional commits",
+			body: "- feat(api): add an endpoint\n- fix: handle errors\n- chore: tidy up\n- refactor!: rename things",
+			want: &Changelog{
+				Features:        []*ChangelogEntry{{Text: "add an endpoint", Scope: "api"}},
+				Fixes:           []*ChangelogEntry{{Text: "handle errors"}},
+				BreakingChanges: []*ChangelogEntry{{Text: "rename things"}},
+			},
+		},
+		{
+			name: "conventional type overrides heading",
+			body: "## Features\n- fix: handle errors",
+			want: &Changelog{
+				Fixes: []*ChangelogEntry{{Text: "handle errors"}},
+			},
+		},
+		{
+			name: "unknown prefix keeps heading and text",
+			body: "## Features\n- API: add an endpoint\n\n## Fixes\n- Parser(v2): fix a typo",
+			want: &Changelog{
+				Features: []*ChangelogEntry{{Text: "API: add an endpoint"}},
+				Fixes:    []*ChangelogEntry{{Text: "Parser(v2): fix a typo"}},
+			},
+		},
+		{
+			name: "breaking change footer",
+			body: "BREAKING CHANGE: the config format changed",
+			want: &Changelog{
+				BreakingChanges: []*ChangelogEntry{{Text: "the config format changed"}},
+			},
+		},
+		{
+			name: "generated notes",
+			body: "## What's Changed\n* feat: add a thing by @JoeBloggs in https://github.com/acme/tools/pull/34\n\n" +
+				"## New Contributors\n* @JaneDoe made their first contribution in #35",
+			want: &Changelog{
+				Features:     []*ChangelogEntry{{Text: "add a thing"}},
+				Contributors: []string{"JoeBloggs", "JaneDoe"},
+				PullRequests: []int{34, 35},
+			},
+		},
+		{
+			name: "pull requests in other repositories",
+			body: "- Fix https://github.com/acme/other/pull/12 and acme/tools#13 (#14)",
+			want: &Changelog{
+				PullRequests: []int{14},
+			},
+		},
+		{
+			name: "code blocks",
+			body: "## Features\n```\n- feat: not an entry @nobody #99\n```\n- Add a thing",
+			want: &Changelog{
+				Features: []*ChangelogEntry{{Text: "Add a thing"}},
+			},
+		},
+		{
+			name: "html entities",
+			body: "- Fix &#34;quotes&#34;",
+			want: &Changelog{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseChangelog(tt.body, repoURL)
+			want := withEmptyLists(tt.want)
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ParseChangelog() = %s, want %s", describe(got), describe(want))
+			}
+		})
+	}
+}
+
+func TestMergedPullRequest(t *testing.T) {
+	tests := []struct {
+		subject string
+		want    int
+	}{
+		{"Merge pull request #34 from acme/branch", 34},
+		{"Add a thing (#35)", 35},
+		{"Add a thing (#35) ", 35},
+		{"Fix #36 in the parser", 0},
+		{"Add a thing", 0},
+		{"Merge branch 'main' into feature", 0},
+		{"", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.subject, func(t *testing.T) {
+			if got := MergedPullRequest(tt.subject); got != tt.want {
+				t.Errorf("MergedPullRequest(%q) = %d, want %d", tt.subject, got, tt.want)
+			}
+		})
+	}
+}
+
+// withEmptyLists returns the changelog with empty lists in place of nil ones, as returned by
+// ParseChangelog.
+func withEmptyLists(c *Changelog) *Changelog {
+	if c.Features == nil {
+		c.Features = []*ChangelogEntry{}
+	}
+
+	if c.Fixes == nil {
+		c.Fixes = []*ChangelogEntry{}
+	}
+
+	if c.BreakingChanges == nil {
+		c.BreakingChanges = []*ChangelogEntry{}
+	}
+
+	if c.Contributors == nil {
+		c.Contributors = []string{}
+	}
+
+	if c.PullRequests == nil {
+		c.PullRequests = []int{}
+	}
+
+	return c
+}
+
+// describe formats a changelog for test failure messages.
+func describe(c *Changelog) string {
+	entries := func(es []*ChangelogEntry) []ChangelogEntry {
+		out := []ChangelogEntry{}
+		for _, e := range es {
+			out = append(out, *e)
+		}
+
+		return out
+	}
+
+	return fmt.Sprintf("{features: %+v, fixes: %+v, breaking: %+v, contributors: %v, pulls: %v}",
+		entries(c.Features), entries(c.Fixes), entries(c.BreakingChanges), c.Contributors, c.PullRequests)
+}
@@ -0,0 +1,38 @@
+package repos
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ID returns the identifier of a repository, which is unique across sources and owners, such as
+// 'github:acme/tools'. Repositories without an owner, such as Launchpad projects, have IDs in
+// the form 'launchpad:tools'. Github owner and repository names are case insensitive, so IDs are
+// always lower case, and two IDs are equal if they refer to the same repository.
+func ID(source, owner, name string) string {
+	if owner == "" {
+		return strings.ToLower(fmt.Sprintf("%s:%s", source, name))
+	}
+
+	return strings.ToLower(fmt.Sprintf("%s:%s/%s", source, owner, name))
+}
+
+// IsID reports whether s is a repository ID, rather than a bare repository name.
+func IsID(s string) bool {
+	return strings.Contains(s, ":")
+}
+
+// ParseID splits a repository ID into its source, owner and name. The owner is empty if the ID
+// has no owner, and ok is false if s is not a repository ID.
+func ParseID(s string) (source, owner, name string, ok bool) {
+	source, rest, ok := strings.Cut(s, ":")
+	if !ok || source == "" || rest == "" {
+		return "", "", "", false
+	}
+
+	if owner, name, found := strings.Cut(rest, "/"); found {
+		return source, owner, name, owner != "" && name != ""
+	}
+
+	return source, "", rest, true
+}
@@ -0,0 +1,50 @@
+package repos
+
+import (
+	"slices"
+	"sync"
+)
+
+// Index holds every repository processed during a run, so that a repository claimed by more
+// than one team is only fetched once, and appears under each of the teams that claim it.
+type Index struct {
+	mu      sync.Mutex
+	entries map[string]*indexEntry // Keyed by repository ID.
+}
+
+// indexEntry holds a single repository in the Index.
+type indexEntry struct {
+	once    sync.Once
+	details *RepoDetails
+}
+
+// NewIndex returns an empty Index.
+func NewIndex() *Index {
+	return &Index{entries: map[string]*indexEntry{}}
+}
+
+// Fetch returns the details of the repository with the given ID, calling process to fetch them
+// if the repository hasn't already been processed during this run. The team is added to the
+// repository's teams. Fetch is safe for concurrent use.
+func (i *Index) Fetch(id, team string, process func() *RepoDetails) *RepoDetails {
+	i.mu.Lock()
+
+	entry, ok := i.entries[id]
+	if !ok {
+		entry = &indexEntry{}
+		i.entries[id] = entry
+	}
+
+	i.mu.Unlock()
+
+	entry.once.Do(func() { entry.details = process() })
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if !slices.Contains(entry.details.Owners, team) {
+		entry.details.Owners = append(entry.details.Owners, team)
+	}
+
+	return entry.details
+}
@@ -0,0 +1,112 @@
+package repos
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// IsRegexpPattern reports whether the pattern is a regular expression, which are enclosed in
+// slashes such as '/^charm-.+$/'.
+func IsRegexpPattern(pattern string) bool {
+	return len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/")
+}
+
+// IsPattern reports whether the pattern is a regular expression or a glob, rather than a
+// plain repository name.
+func IsPattern(pattern string) bool {
+	return IsRegexpPattern(pattern) || strings.ContainsAny(pattern, `*?[\`)
+}
+
+// Pattern is a compiled repository pattern. Patterns enclosed in slashes are regular
+// expressions, and all others are globs, such as 'charm-*'. A plain repository name is a glob
+// that only matches itself.
+type Pattern struct {
+	raw string
+	re  *regexp.Regexp
+	// id is set for globs of repository IDs, which are matched case insensitively as IDs are
+	// lower case.
+	id bool
+}
+
+// Patterns is a list of compiled repository patterns, which are compiled once by
+// CompilePatterns when the config is validated, rather than every time they're matched.
+type Patterns []*Pattern
+
+// CompilePatterns compiles each of the patterns, returning an error if any of them is not a
+// valid regular expression or glob.
+func CompilePatterns(patterns []string) (Patterns, error) {
+	compiled := Patterns{}
+
+	for _, p := range patterns {
+		if IsRegexpPattern(p) {
+			re, err := regexp.Compile(p[1 : len(p)-1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern '%s': %w", p, err)
+			}
+
+			compiled = append(compiled, &Pattern{raw: p, re: re})
+
+			continue
+		}
+
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern '%s': %w", p, err)
+		}
+
+		compiled = append(compiled, &Pattern{raw: p, id: IsID(p)})
+	}
+
+	return compiled, nil
+}
+
+// Matches reports whether name matches the pattern.
+func (p *Pattern) Matches(name string) bool {
+	if p.re != nil {
+		return p.re.MatchString(name)
+	}
+
+	glob := p.raw
+	if p.id {
+		glob = strings.ToLower(glob)
+	}
+
+	matched, _ := path.Match(glob, name)
+
+	return matched
+}
+
+// String returns the pattern as it was written in the config.
+func (p *Pattern) String() string {
+	return p.raw
+}
+
+// MatchesRepo reports whether either the name or the ID of a repository matches the pattern,
+// so that a repository can be ignored by name, or by ID where the name is ambiguous.
+func (p *Pattern) MatchesRepo(id, name string) bool {
+	return p.Matches(name) || p.Matches(id)
+}
+
+// MatchesAny reports whether name matches any of the patterns.
+func (ps Patterns) MatchesAny(name string) bool {
+	for _, p := range ps {
+		if p.Matches(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MatchesRepo reports whether either the name or the ID of a repository matches any of the
+// patterns.
+func (ps Patterns) MatchesRepo(id, name string) bool {
+	for _, p := range ps {
+		if p.MatchesRepo(id, name) {
+			return true
+		}
+	}
+
+	return false
+}
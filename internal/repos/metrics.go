@@ -0,0 +1,148 @@
+package repos
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// Day is the length of a day, used to express durations in days.
+const Day = 24 * time.Hour
+
+// Metrics describes how often a repository is released, and how much work is waiting to be
+// released. Metrics that can't be calculated from the data gathered are null, such as release
+// counts for periods that go back further than the releases or tags that were fetched.
+type Metrics struct {
+	DaysSinceRelease          *int     `json:"daysSinceRelease"`
+	MedianReleaseIntervalDays *float64 `json:"medianReleaseIntervalDays"`
+	ReleasesLast30Days        *int     `json:"releasesLast30Days"`
+	ReleasesLast90Days        *int     `json:"releasesLast90Days"`
+	ReleasesLast365Days       *int     `json:"releasesLast365Days"`
+	DaysToStore               *float64 `json:"daysToStore"`
+	UnreleasedAgeDays         *int     `json:"unreleasedAgeDays"`
+	Stale                     bool     `json:"stale"`
+	NeedsRelease              bool     `json:"needsRelease"`
+}
+
+// Thresholds configure when a repository is flagged as stale, or as needing a release. A
+// threshold of zero is disabled.
+type Thresholds struct {
+	// StaleDays is the number of days without a release after which a repository is stale.
+	StaleDays int `mapstructure:"stale-days"`
+	// NeedsReleaseDays is the age in days of the oldest unreleased commit after which a
+	// repository needs a release.
+	NeedsReleaseDays int `mapstructure:"needs-release-days"`
+	// NeedsReleaseCommits is the number of unreleased commits after which a repository needs a
+	// release.
+	NeedsReleaseCommits int `mapstructure:"needs-release-commits"`
+}
+
+// ComputeMetrics calculates the repository's metrics at the given time, from the history of
+// its releases, or tags if it has no releases, and its unreleased commits. If the history is
+// truncated, the median release interval isn't calculated, and neither are the release counts
+// for periods that the history might not cover.
+func (r *RepoDetails) ComputeMetrics(now time.Time, thresholds Thresholds) {
+	metrics := &Metrics{}
+
+	history := slices.Clone(r.ReleaseHistory)
+	slices.Sort(history)
+	slices.Reverse(history)
+
+	if len(history) > 0 {
+		latest := time.Unix(history[0], 0)
+		days := int(now.Sub(latest) / Day)
+		metrics.DaysSinceRelease = &days
+		metrics.DaysToStore = r.daysToStore(history[0])
+	}
+
+	metrics.ReleasesLast30Days = r.releasesSince(history, now, 30)
+	metrics.ReleasesLast90Days = r.releasesSince(history, now, 90)
+	metrics.ReleasesLast365Days = r.releasesSince(history, now, 365)
+
+	intervals := []float64{}
+
+	for i := 1; i < len(history); i++ {
+		intervals = append(intervals, float64(history[i-1]-history[i])/Day.Seconds())
+	}
+
+	if len(intervals) > 0 && !r.HistoryTruncated {
+		interval := RoundDays(Median(intervals))
+		metrics.MedianReleaseIntervalDays = &interval
+	}
+
+	if r.OldestUnreleased > 0 {
+		age := int(now.Sub(time.Unix(r.OldestUnreleased, 0)) / Day)
+		metrics.UnreleasedAgeDays = &age
+	}
+
+	metrics.Stale = thresholds.StaleDays > 0 && metrics.DaysSinceRelease != nil &&
+		*metrics.DaysSinceRelease > thresholds.StaleDays
+	metrics.NeedsRelease = (thresholds.NeedsReleaseDays > 0 && metrics.UnreleasedAgeDays != nil &&
+		*metrics.UnreleasedAgeDays > thresholds.NeedsReleaseDays) ||
+		(thresholds.NeedsReleaseCommits > 0 && r.NewCommits >= thresholds.NeedsReleaseCommits)
+
+	r.Metrics = metrics
+}
+
+// releasesSince returns the number of releases in the history, which is sorted newest first, in
+// the given number of days before now. If the history is truncated, the count is only known if
+// its oldest release is older than that, otherwise nil is returned.
+func (r *RepoDetails) releasesSince(history []int64, now time.Time, days int) *int {
+	window := time.Duration(days) * Day
+
+	if r.HistoryTruncated && (len(history) == 0 || now.Sub(time.Unix(history[len(history)-1], 0)) <= window) {
+		return nil
+	}
+
+	count := 0
+
+	for _, ts := range history {
+		if now.Sub(time.Unix(ts, 0)) <= window {
+			count++
+		}
+	}
+
+	return &count
+}
+
+// daysToStore returns the number of days from the latest release until the earliest release to
+// a store channel after it, or nil if none of the repository's artifacts has been released since.
+// Stores only report the latest release in each channel, so this is the time until the first of
+// the current releases, rather than the time until the first ever release.
+func (r *RepoDetails) daysToStore(released int64) *float64 {
+	first := int64(math.MaxInt64)
+
+	for _, artifact := range slices.Concat(r.Snaps, r.Charms) {
+		for _, rel := range artifact.Releases {
+			if rel.Timestamp >= released && rel.Timestamp < first {
+				first = rel.Timestamp
+			}
+		}
+	}
+
+	if first == math.MaxInt64 {
+		return nil
+	}
+
+	days := RoundDays(float64(first-released) / Day.Seconds())
+
+	return &days
+}
+
+// Median returns the median of a non-empty slice of values.
+func Median(values []float64) float64 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+
+	return sorted[mid]
+}
+
+// RoundDays rounds a number of days to one decimal place.
+func RoundDays(days float64) float64 {
+	return math.Round(days*10) / 10
+}
@@ -0,0 +1,140 @@
+package repos
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jnsgruk/releasegen/internal/stores"
+)
+
+func TestComputeMetrics(t *testing.T) {
+	now := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+	daysAgo := func(days float64) int64 { return now.Add(-time.Duration(days * float64(Day))).Unix() }
+
+	tests := []struct {
+		name       string
+		details    *RepoDetails
+		thresholds Thresholds
+		want       *Metrics
+	}{
+		{
+			name:    "no releases",
+			details: &RepoDetails{},
+			want: &Metrics{
+				ReleasesLast30Days:  ptr(0),
+				ReleasesLast90Days:  ptr(0),
+				ReleasesLast365Days: ptr(0),
+			},
+		},
+		{
+			name:       "release history",
+			details:    &RepoDetails{ReleaseHistory: []int64{daysAgo(40), daysAgo(10), daysAgo(100)}},
+			thresholds: Thresholds{StaleDays: 5},
+			want: &Metrics{
+				DaysSinceRelease:          ptr(10),
+				MedianReleaseIntervalDays: ptr(45.0),
+				ReleasesLast30Days:        ptr(1),
+				ReleasesLast90Days:        ptr(2),
+				ReleasesLast365Days:       ptr(3),
+				Stale:                     true,
+			},
+		},
+		{
+			name: "truncated history",
+			details: &RepoDetails{
+				ReleaseHistory:   []int64{daysAgo(10), daysAgo(40), daysAgo(100)},
+				HistoryTruncated: true,
+			},
+			thresholds: Thresholds{StaleDays: 30},
+			want: &Metrics{
+				DaysSinceRelease:   ptr(10),
+				ReleasesLast30Days: ptr(1),
+				ReleasesLast90Days: ptr(2),
+			},
+		},
+		{
+			name:    "truncated empty history",
+			details: &RepoDetails{HistoryTruncated: true},
+			want:    &Metrics{},
+		},
+		{
+			name: "days to store",
+			details: &RepoDetails{
+				ReleaseHistory: []int64{daysAgo(10)},
+				Snaps: []*stores.Artifact{{Releases: []*stores.Release{
+					{Timestamp: daysAgo(12)},
+					{Timestamp: daysAgo(7.5)},
+					{Timestamp: daysAgo(2)},
+				}}},
+			},
+			want: &Metrics{
+				DaysSinceRelease:    ptr(10),
+				ReleasesLast30Days:  ptr(1),
+				ReleasesLast90Days:  ptr(1),
+				ReleasesLast365Days: ptr(1),
+				DaysToStore:         ptr(2.5),
+			},
+		},
+		{
+			name:       "needs release by age",
+			details:    &RepoDetails{ReleaseHistory: []int64{daysAgo(30)}, OldestUnreleased: daysAgo(20), NewCommits: 1},
+			thresholds: Thresholds{NeedsReleaseDays: 14, NeedsReleaseCommits: 10},
+			want: &Metrics{
+				DaysSinceRelease:    ptr(30),
+				ReleasesLast30Days:  ptr(1),
+				ReleasesLast90Days:  ptr(1),
+				ReleasesLast365Days: ptr(1),
+				UnreleasedAgeDays:   ptr(20),
+				NeedsRelease:        true,
+			},
+		},
+		{
+			name:       "needs release by commits",
+			details:    &RepoDetails{OldestUnreleased: daysAgo(2), NewCommits: 10},
+			thresholds: Thresholds{NeedsReleaseDays: 14, NeedsReleaseCommits: 10},
+			want: &Metrics{
+				ReleasesLast30Days:  ptr(0),
+				ReleasesLast90Days:  ptr(0),
+				ReleasesLast365Days: ptr(0),
+				UnreleasedAgeDays:   ptr(2),
+				NeedsRelease:        true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.details.ComputeMetrics(now, tt.thresholds)
+
+			if !reflect.DeepEqual(tt.details.Metrics, tt.want) {
+				got, _ := json.Marshal(tt.details.Metrics)
+				want, _ := json.Marshal(tt.want)
+				t.Errorf("ComputeMetrics() = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestMedian(t *testing.T) {
+	tests := []struct {
+		values []float64
+		want   float64
+	}{
+		{[]float64{3}, 3},
+		{[]float64{5, 1, 3}, 3},
+		{[]float64{4, 1, 3, 2}, 2.5},
+	}
+
+	for _, tt := range tests {
+		if got := Median(tt.values); got != tt.want {
+			t.Errorf("Median(%v) = %v, want %v", tt.values, got, tt.want)
+		}
+	}
+}
+
+// ptr returns a pointer to the value.
+func ptr[T any](v T) *T {
+	return &v
+}
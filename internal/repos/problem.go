@@ -0,0 +1,51 @@
+package repos
+
+import "github.com/jnsgruk/releasegen/internal/credentials"
+
+// The stages of processing at which a Problem can occur.
+const (
+	StageRepos    = "repos"
+	StageReleases = "releases"
+	StageTags     = "tags"
+	StageCommits  = "commits"
+	StageReadme   = "readme"
+	StageMetadata = "metadata"
+	StageSnap     = "snap"
+	StageCharm    = "charm"
+	StagePackage  = "package"
+	// StageConfig and StageCredentials are run level stages, at which no team was processed.
+	StageConfig      = "config"
+	StageCredentials = "credentials"
+)
+
+// Problem describes an error or warning encountered while gathering data for the report.
+type Problem struct {
+	Source  string `json:"source"`
+	Stage   string `json:"stage"`
+	Message string `json:"message"`
+
+	// err is the error the problem describes, which isn't known for problems read from a report.
+	err error
+}
+
+// NewProblem returns a Problem describing err, encountered at the given source and stage. Any
+// secrets in the error message are redacted, as the problem is published in the report.
+func NewProblem(source, stage string, err error) *Problem {
+	return &Problem{Source: source, Stage: stage, Message: credentials.Redact(err.Error()), err: err}
+}
+
+// Err returns the error that the problem describes, so that its cause can be checked with
+// errors.Is, or nil if the problem was read from a report.
+func (p *Problem) Err() error {
+	return p.err
+}
+
+// AddError records an error that left the repository's details incomplete.
+func (r *RepoDetails) AddError(source, stage string, err error) {
+	r.Errors = append(r.Errors, NewProblem(source, stage, err))
+}
+
+// AddWarning records a problem that didn't prevent the repository's release data being gathered.
+func (r *RepoDetails) AddWarning(source, stage string, err error) {
+	r.Warnings = append(r.Warnings, NewProblem(source, stage, err))
+}
@@ -2,8 +2,11 @@ package repos
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"regexp"
+	"slices"
+	"strings"
 
 	"github.com/jnsgruk/releasegen/internal/stores"
 )
@@ -15,6 +18,15 @@ var (
 	charmBadgeRegexp = regexp.MustCompile(`https://charmhub.io/(?P<Name>[\w-]+)/badge.svg`)
 	// snapBadgeRegexp is used to find a Snap's name in its Snapcraft badge.
 	snapBadgeRegexp = regexp.MustCompile(`https://snapcraft.io/(?P<Name>[\w-]+)/badge.svg`)
+	// packageBadgeRegexps are used to find the names of packages in the badges of each registry,
+	// such as shields.io version badges, and pkg.go.dev badges for Go modules. Names may be
+	// followed by a '.svg' extension, which is removed.
+	packageBadgeRegexps = map[string]*regexp.Regexp{
+		stores.RegistryPyPI:   regexp.MustCompile(`https://img.shields.io/pypi/v/(?P<Name>[\w.-]+)`),
+		stores.RegistryCrates: regexp.MustCompile(`https://img.shields.io/crates/v/(?P<Name>[\w-]+)`),
+		stores.RegistryNPM:    regexp.MustCompile(`https://img.shields.io/npm/v/(?P<Name>(?:@[\w.-]+/)?[\w.-]+)`),
+		stores.RegistryGo:     regexp.MustCompile(`https://pkg.go.dev/badge/(?P<Name>[\w.~/-]+?)\.svg`),
+	}
 )
 
 type Readme struct {
@@ -37,48 +49,138 @@ func (r *Readme) GithubActions() []string {
 	return actions
 }
 
-// LinkedSnap parses the Readme body, and returns a StoreArtifact representing a snap
-// if there is a Snapcraft.io badge in the Readme.
-func (r *Readme) LinkedSnap(ctx context.Context) *stores.Artifact {
-	// If the README has a Snapcraft Badge, fetch the snap information.
-	if snapName := getArtifactName(r.Body, snapBadgeRegexp); snapName != "" {
-		snapInfo, err := stores.FetchSnapDetails(ctx, snapName)
+// SnapNames returns the names of the snaps with a Snapcraft badge in the Readme.
+func (r *Readme) SnapNames() []string {
+	return artifactNames(r.Body, snapBadgeRegexp)
+}
+
+// CharmNames returns the names of the charms with a Charmhub badge in the Readme.
+func (r *Readme) CharmNames() []string {
+	return artifactNames(r.Body, charmBadgeRegexp)
+}
+
+// PackageNames returns the names of the packages with a badge for the named registry in the
+// Readme.
+func (r *Readme) PackageNames(registry string) []string {
+	return artifactNames(r.Body, packageBadgeRegexps[registry])
+}
+
+// PopulateArtifacts sets the snaps, charms and packages published from the repository on its
+// details. For each kind of artifact, those declared in the config are used if the kind is
+// declared, even as an empty list, otherwise those with a badge in the Readme are. A warning is
+// recorded for any artifact that could not be fetched from its store or registry.
+func (r *Readme) PopulateArtifacts(ctx context.Context, details *RepoDetails, declared *ArtifactConfig) {
+	snaps, charms := r.SnapNames(), r.CharmNames()
+
+	if declared != nil && declared.Snaps != nil {
+		snaps = declared.Snaps
+	}
+
+	if declared != nil && declared.Charms != nil {
+		charms = declared.Charms
+	}
+
+	for _, name := range snaps {
+		snapInfo, err := stores.FetchSnapDetails(ctx, name)
+		if err != nil {
+			log.Printf("failed to fetch snap package information for snap: %s", name)
+			details.AddWarning("snapcraft", StageSnap, fmt.Errorf("failed to fetch snap '%s': %w", name, err))
+
+			continue
+		}
+
+		details.Snaps = append(details.Snaps, stores.NewArtifact(name, snapInfo))
+	}
+
+	for _, name := range charms {
+		charmInfo, err := stores.FetchCharmDetails(ctx, name)
 		if err != nil {
-			log.Printf("failed to fetch snap package information for snap: %s", snapName)
-			return nil
+			log.Printf("failed to fetch charm information for charm: %s", name)
+			details.AddWarning("charmhub", StageCharm, fmt.Errorf("failed to fetch charm '%s': %w", name, err))
+
+			continue
+		}
+
+		if charmInfo.LibrariesErr != nil {
+			details.AddWarning("charmhub", StageCharm,
+				fmt.Errorf("failed to fetch libraries of charm '%s': %w", name, charmInfo.LibrariesErr))
 		}
 
-		return stores.NewArtifact(snapName, snapInfo)
+		details.Charms = append(details.Charms, stores.NewArtifact(name, charmInfo))
 	}
 
-	return nil
+	for _, registry := range stores.Registries {
+		names := r.PackageNames(registry)
+		if declared != nil && declared.Packages(registry) != nil {
+			names = declared.Packages(registry)
+		}
+
+		for _, name := range names {
+			pkg, err := stores.FetchPackage(ctx, registry, name, details.versions())
+			if err != nil {
+				log.Printf("failed to fetch %s package information for package: %s", registry, name)
+				details.AddWarning(registry, StagePackage, fmt.Errorf("failed to fetch package '%s': %w", name, err))
+
+				continue
+			}
+
+			details.Packages = append(details.Packages, pkg)
+		}
+	}
+
+	details.matchPublished()
 }
 
-// LinkedCharm parses the Readme body, and returns a StoreArtifact representing a charm
-// if there is a Charmhub.io badge in the Readme.
-func (r *Readme) LinkedCharm(ctx context.Context) *stores.Artifact {
-	// If the README has a Charmhub Badge, fetch the charm information
-	if charmName := getArtifactName(r.Body, charmBadgeRegexp); charmName != "" {
-		charmInfo, err := stores.FetchCharmDetails(ctx, charmName)
-		if err != nil {
-			log.Printf("failed to fetch charm information for charm: %s", charmName)
-			return nil
+// versions returns the versions of the repository's releases and tags.
+func (r *RepoDetails) versions() []string {
+	versions := []string{}
+
+	for _, rel := range r.Releases {
+		versions = append(versions, rel.Version)
+	}
+
+	for _, tag := range r.Tags {
+		versions = append(versions, tag.Name)
+	}
+
+	return versions
+}
+
+// matchPublished records the packages that each release and tag's version was published in.
+func (r *RepoDetails) matchPublished() {
+	published := func(version string) []string {
+		ids := []string{}
+
+		for _, pkg := range r.Packages {
+			if pkg.HasVersion(version) {
+				ids = append(ids, pkg.ID())
+			}
 		}
 
-		return stores.NewArtifact(charmName, charmInfo)
+		return ids
+	}
+
+	for _, rel := range r.Releases {
+		rel.Published = published(rel.Version)
 	}
 
-	return nil
+	for _, tag := range r.Tags {
+		tag.Published = published(tag.Name)
+	}
 }
 
-// getArtifactName tries to parse an artifact name from a store badge in repo's README.
-func getArtifactName(readme string, re *regexp.Regexp) string {
+// artifactNames parses the names of artifacts from the store badges in a repo's README, in the
+// order they first appear.
+func artifactNames(readme string, re *regexp.Regexp) []string {
 	nameIndex := re.SubexpIndex("Name")
+	names := []string{}
 
-	matches := re.FindStringSubmatch(readme)
-	if len(matches) > 0 {
-		return matches[nameIndex]
+	for _, matches := range re.FindAllStringSubmatch(readme, -1) {
+		name := strings.TrimSuffix(matches[nameIndex], ".svg")
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
 	}
 
-	return ""
+	return names
 }
@@ -0,0 +1,94 @@
+package repos
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jnsgruk/releasegen/internal/stores"
+)
+
+func TestReadmeArtifactNames(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		names func(r *Readme) []string
+		want  []string
+	}{
+		{
+			name:  "snaps",
+			body:  "[![lxd](https://snapcraft.io/lxd/badge.svg)](https://snapcraft.io/lxd) ![](https://snapcraft.io/lxd-ui/badge.svg)",
+			names: (*Readme).SnapNames,
+			want:  []string{"lxd", "lxd-ui"},
+		},
+		{
+			name:  "duplicate snaps",
+			body:  "![](https://snapcraft.io/lxd/badge.svg) ![](https://snapcraft.io/lxd/badge.svg)",
+			names: (*Readme).SnapNames,
+			want:  []string{"lxd"},
+		},
+		{
+			name:  "snap store page is not a badge",
+			body:  "[lxd](https://snapcraft.io/lxd)",
+			names: (*Readme).SnapNames,
+			want:  []string{},
+		},
+		{
+			name:  "charms",
+			body:  "[![Charmhub](https://charmhub.io/postgresql-k8s/badge.svg)](https://charmhub.io/postgresql-k8s)",
+			names: (*Readme).CharmNames,
+			want:  []string{"postgresql-k8s"},
+		},
+		{
+			name:  "pypi",
+			body:  "![PyPI](https://img.shields.io/pypi/v/ops.scenario) ![](https://img.shields.io/pypi/v/ops?label=ops)",
+			names: packageNames(stores.RegistryPyPI),
+			want:  []string{"ops.scenario", "ops"},
+		},
+		{
+			name:  "crates",
+			body:  "![](https://img.shields.io/crates/v/serde-json.svg)",
+			names: packageNames(stores.RegistryCrates),
+			want:  []string{"serde-json"},
+		},
+		{
+			name:  "npm",
+			body:  "![](https://img.shields.io/npm/v/@canonical/react-components) ![](https://img.shields.io/npm/v/vanilla-framework.svg)",
+			names: packageNames(stores.RegistryNPM),
+			want:  []string{"@canonical/react-components", "vanilla-framework"},
+		},
+		{
+			name:  "go",
+			body:  "[![Go Reference](https://pkg.go.dev/badge/github.com/canonical/go-dqlite/v2.svg)](https://pkg.go.dev/github.com/canonical/go-dqlite/v2)",
+			names: packageNames(stores.RegistryGo),
+			want:  []string{"github.com/canonical/go-dqlite/v2"},
+		},
+		{
+			name:  "badges for other registries",
+			body:  "![](https://img.shields.io/pypi/v/ops) ![](https://snapcraft.io/lxd/badge.svg)",
+			names: packageNames(stores.RegistryNPM),
+			want:  []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.names(&Readme{Body: tt.body}); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("names = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadmeGithubActions(t *testing.T) {
+	body := "[![CI](https://github.com/acme/tools/actions/workflows/ci.yaml/badge.svg)](https://github.com/acme/tools/actions)"
+	want := []string{"https://github.com/acme/tools/actions/workflows/ci.yaml"}
+
+	if got := (&Readme{Body: body}).GithubActions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("GithubActions() = %q, want %q", got, want)
+	}
+}
+
+// packageNames returns a function that lists the packages with a badge for the registry.
+func packageNames(registry string) func(r *Readme) []string {
+	return func(r *Readme) []string { return r.PackageNames(registry) }
+}
@@ -1,22 +1,55 @@
 package repos
 
-import (
-	"slices"
+import "github.com/jnsgruk/releasegen/internal/stores"
 
-	"github.com/jnsgruk/releasegen/internal/stores"
-)
+// DefaultMaxUnreleased is the number of unreleased commits listed for each repository, unless
+// configured otherwise.
+const DefaultMaxUnreleased = 20
 
 // RepoDetails represents the serialisable form of a Repository for the Report.
 type RepoDetails struct {
-	Name       string           `json:"name"`
-	NewCommits int              `json:"newCommits"`
-	URL        string           `json:"url"`
-	Releases   []*Release       `json:"releases"`
-	Tags       []*Tag           `json:"tags"`
-	Commits    []*Commit        `json:"commits"`
-	CiActions  []string         `json:"ciActions"`
-	Charm      *stores.Artifact `json:"charm"`
-	Snap       *stores.Artifact `json:"snap"`
+	ID               string             `json:"id"`
+	Source           string             `json:"source"`
+	Owner            string             `json:"owner"`
+	Name             string             `json:"name"`
+	Owners           []string           `json:"owners"`
+	NewCommits       int                `json:"newCommits"`
+	Unreleased       []*Unreleased      `json:"unreleased"`
+	URL              string             `json:"url"`
+	Description      string             `json:"description"`
+	Topics           []string           `json:"topics"`
+	Language         string             `json:"language"`
+	License          string             `json:"license"`
+	Stars            int                `json:"stars"`
+	OpenIssues       int                `json:"openIssues"`
+	OpenPullRequests int                `json:"openPullRequests"`
+	DefaultBranch    string             `json:"defaultBranch"`
+	Homepage         string             `json:"homepage"`
+	PushedAt         int64              `json:"pushedAt"`
+	Archived         bool               `json:"archived"`
+	Private          bool               `json:"private"`
+	Fork             bool               `json:"fork"`
+	Template         bool               `json:"template"`
+	Releases         []*Release         `json:"releases"`
+	Tags             []*Tag             `json:"tags"`
+	Commits          []*Commit          `json:"commits"`
+	CiActions        []string           `json:"ciActions"`
+	Charms           []*stores.Artifact `json:"charms"`
+	Snaps            []*stores.Artifact `json:"snaps"`
+	Packages         []*stores.Package  `json:"packages"`
+	Metrics          *Metrics           `json:"metrics"`
+	Errors           []*Problem         `json:"errors"`
+	Warnings         []*Problem         `json:"warnings"`
+
+	// ReleaseHistory holds the timestamps of the repository's releases, or tags if it has no
+	// releases, which may go back further than Releases or Tags.
+	ReleaseHistory []int64 `json:"-"`
+	// HistoryTruncated is set if the repository has older releases or tags than those in
+	// ReleaseHistory, so that metrics which need the full history aren't calculated.
+	HistoryTruncated bool `json:"-"`
+	// OldestUnreleased is the timestamp of the oldest commit on the default branch since the
+	// latest release or tag, or zero if there are none.
+	OldestUnreleased int64 `json:"-"`
 }
 
 // Repository is an interface that provides common methods for different types of repository.
@@ -26,39 +59,49 @@ type Repository interface {
 
 // Release refers to either Github Release.
 type Release struct {
-	ID         int64  `json:"id"`
-	Version    string `json:"version"`
-	Timestamp  int64  `json:"timestamp"`
-	Title      string `json:"title"`
-	Body       string `json:"body"`
-	URL        string `json:"url"`
-	CompareURL string `json:"compareUrl"`
+	ID           int64      `json:"id"`
+	Version      string     `json:"version"`
+	Timestamp    int64      `json:"timestamp"`
+	Title        string     `json:"title"`
+	Body         string     `json:"body"`
+	BodyMarkdown string     `json:"bodyMarkdown,omitempty"`
+	Summary      string     `json:"summary"`
+	Changelog    *Changelog `json:"changelog"`
+	Published    []string   `json:"published"`
+	URL          string     `json:"url"`
+	CompareURL   string     `json:"compareUrl"`
 }
 
 // Tag refers to a tag.
 type Tag struct {
-	Name       string `json:"name"`
-	Sha        string `json:"sha"`
-	Body       string `json:"body"`
-	Timestamp  int64  `json:"timestamp"`
-	URL        string `json:"url"`
-	CompareURL string `json:"compareUrl"`
+	Name         string   `json:"name"`
+	Sha          string   `json:"sha"`
+	Body         string   `json:"body"`
+	BodyMarkdown string   `json:"bodyMarkdown,omitempty"`
+	Summary      string   `json:"summary"`
+	Timestamp    int64    `json:"timestamp"`
+	Published    []string `json:"published"`
+	URL          string   `json:"url"`
+	CompareURL   string   `json:"compareUrl"`
 }
 
 // Commit represents a Git commit.
 type Commit struct {
-	Sha       string `json:"sha"`
-	Author    string `json:"author"`
-	Timestamp int64  `json:"timestamp"`
-	Message   string `json:"message"`
-	URL       string `json:"url"`
+	Sha          string `json:"sha"`
+	Author       string `json:"author"`
+	Timestamp    int64  `json:"timestamp"`
+	Message      string `json:"message"`
+	BodyMarkdown string `json:"bodyMarkdown,omitempty"`
+	Summary      string `json:"summary"`
+	URL          string `json:"url"`
 }
 
-// repoInSlice is a helper function to test if a given repo is already in a list of repos.
-func RepoInSlice(repositories []RepoDetails, r string) bool {
-	index := slices.IndexFunc(repositories, func(repo RepoDetails) bool {
-		return repo.Name == r
-	})
-
-	return index >= 0
+// Unreleased represents a commit on the default branch that hasn't been released yet.
+type Unreleased struct {
+	Sha         string `json:"sha"`
+	Subject     string `json:"subject"`
+	Author      string `json:"author"`
+	Timestamp   int64  `json:"timestamp"`
+	PullRequest int    `json:"pullRequest,omitempty"`
+	URL         string `json:"url"`
 }
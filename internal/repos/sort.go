@@ -0,0 +1,103 @@
+package repos
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// The orders that repositories can be sorted in.
+const (
+	// SortActivity sorts repositories by their latest release, tag or commit, newest first. This
+	// is the default.
+	SortActivity = "activity"
+	// SortName sorts repositories alphabetically by name.
+	SortName = "name"
+	// SortVersion sorts repositories by their latest release or tag, newest first.
+	SortVersion = "version"
+	// SortNewCommits sorts repositories by the number of commits since their latest release or
+	// tag, most first.
+	SortNewCommits = "new-commits"
+	// SortStore sorts repositories by the latest release of their snap or charm to a store
+	// channel, newest first.
+	SortStore = "store"
+)
+
+// SortOrders are the supported values of the sort option.
+//
+//nolint:gochecknoglobals
+var SortOrders = []string{SortActivity, SortName, SortVersion, SortNewCommits, SortStore}
+
+// LatestActivity returns the timestamp of the repository's latest release, tag or commit, or
+// zero if it has none.
+func (r *RepoDetails) LatestActivity() int64 {
+	latest := r.LatestVersion()
+
+	for _, c := range r.Commits {
+		latest = max(latest, c.Timestamp)
+	}
+
+	for _, u := range r.Unreleased {
+		latest = max(latest, u.Timestamp)
+	}
+
+	return latest
+}
+
+// LatestVersion returns the timestamp of the repository's latest release or tag, or zero if it
+// has neither.
+func (r *RepoDetails) LatestVersion() int64 {
+	latest := int64(0)
+
+	for _, rel := range r.Releases {
+		latest = max(latest, rel.Timestamp)
+	}
+
+	for _, tag := range r.Tags {
+		latest = max(latest, tag.Timestamp)
+	}
+
+	return latest
+}
+
+// LatestStoreRelease returns the timestamp of the latest release of the repository's snaps or
+// charms to a store channel, or zero if it has none.
+func (r *RepoDetails) LatestStoreRelease() int64 {
+	latest := int64(0)
+
+	for _, artifact := range slices.Concat(r.Snaps, r.Charms) {
+		for _, rel := range artifact.Releases {
+			latest = max(latest, rel.Timestamp)
+		}
+	}
+
+	return latest
+}
+
+// Sort sorts repositories in the given order, which defaults to SortActivity. Ties, such as
+// repositories with no activity at all, are broken by name and then ID, so that the order is
+// always the same.
+func Sort(repositories []*RepoDetails, order string) {
+	var key func(r *RepoDetails) int64
+
+	switch order {
+	case SortName:
+		key = func(*RepoDetails) int64 { return 0 }
+	case SortVersion:
+		key = (*RepoDetails).LatestVersion
+	case SortNewCommits:
+		key = func(r *RepoDetails) int64 { return int64(r.NewCommits) }
+	case SortStore:
+		key = (*RepoDetails).LatestStoreRelease
+	default:
+		key = (*RepoDetails).LatestActivity
+	}
+
+	slices.SortStableFunc(repositories, func(a, b *RepoDetails) int {
+		return cmp.Or(
+			cmp.Compare(key(b), key(a)),
+			cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)),
+			cmp.Compare(a.ID, b.ID),
+		)
+	})
+}
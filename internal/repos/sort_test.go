@@ -0,0 +1,66 @@
+package repos
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jnsgruk/releasegen/internal/stores"
+)
+
+func TestSort(t *testing.T) {
+	repositories := func() []*RepoDetails {
+		return []*RepoDetails{
+			{
+				ID:         "github:acme/bravo",
+				Name:       "bravo",
+				Releases:   []*Release{{Timestamp: 300}},
+				Unreleased: []*Unreleased{{Timestamp: 500}},
+				NewCommits: 1,
+			},
+			{
+				ID:         "github:acme/alpha",
+				Name:       "Alpha",
+				Tags:       []*Tag{{Timestamp: 100}},
+				Commits:    []*Commit{{Timestamp: 400}},
+				NewCommits: 5,
+			},
+			{
+				ID:    "github:acme/charlie",
+				Name:  "charlie",
+				Snaps: []*stores.Artifact{{Releases: []*stores.Release{{Timestamp: 200}}}},
+			},
+			{
+				ID:   "launchpad:charlie",
+				Name: "charlie",
+			},
+		}
+	}
+
+	tests := []struct {
+		order string
+		want  []string
+	}{
+		{"", []string{"github:acme/bravo", "github:acme/alpha", "github:acme/charlie", "launchpad:charlie"}},
+		{SortActivity, []string{"github:acme/bravo", "github:acme/alpha", "github:acme/charlie", "launchpad:charlie"}},
+		{SortName, []string{"github:acme/alpha", "github:acme/bravo", "github:acme/charlie", "launchpad:charlie"}},
+		{SortVersion, []string{"github:acme/bravo", "github:acme/alpha", "github:acme/charlie", "launchpad:charlie"}},
+		{SortNewCommits, []string{"github:acme/alpha", "github:acme/bravo", "github:acme/charlie", "launchpad:charlie"}},
+		{SortStore, []string{"github:acme/charlie", "github:acme/alpha", "github:acme/bravo", "launchpad:charlie"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			repos := repositories()
+			Sort(repos, tt.order)
+
+			got := []string{}
+			for _, r := range repos {
+				got = append(got, r.ID)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Sort(%q) = %q, want %q", tt.order, got, tt.want)
+			}
+		})
+	}
+}
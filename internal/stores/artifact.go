@@ -1,6 +1,11 @@
 package stores
 
 import (
+	"cmp"
+	"context"
+	"errors"
+	"io"
+	"net/http"
 	"slices"
 	"time"
 
@@ -14,6 +19,10 @@ type Artifact struct {
 	Releases []*Release `json:"releases"`
 	Channels []string   `json:"channels"`
 	Tracks   []string   `json:"tracks"`
+	// ChannelMap groups the releases by channel, such as 'latest/stable'.
+	ChannelMap []*Channel `json:"channelMap"`
+	// Libraries are the charm libraries published by a charm.
+	Libraries []*Library `json:"libraries"`
 }
 
 // NewArtifact returns a representation of an artifact with its releases/tracks/channels populated.
@@ -29,18 +38,23 @@ func NewArtifact(name string, details *ArtifactDetails) *Artifact {
 		track := details.Tracks[index].String()
 		channel := details.Channels[index].String()
 
-		base := ""
-		if len(details.Bases) > 0 {
-			base = details.Bases[index].String()
+		release := &Release{
+			Track:        track,
+			Channel:      channel,
+			Revision:     details.Revisions[index].Int(),
+			Timestamp:    parsedTime.Unix(),
+			Base:         baseName(at(details.Bases, index)),
+			Architecture: at(details.Architectures, index).String(),
+			Version:      at(details.Versions, index).String(),
+			Confinement:  at(details.Confinements, index).String(),
+			Grade:        at(details.Grades, index).String(),
+			Size:         at(details.Sizes, index).Int(),
+			Bases:        newBases(at(details.RevisionBases, index)),
+			Resources:    newResources(at(details.Resources, index)),
 		}
 
-		artifact.Releases = append(artifact.Releases, &Release{
-			Track:     track,
-			Channel:   channel,
-			Revision:  details.Revisions[index].Int(),
-			Timestamp: parsedTime.Unix(),
-			Base:      base,
-		})
+		artifact.Releases = append(artifact.Releases, release)
+		artifact.addToChannelMap(release)
 
 		if !slices.Contains(artifact.Tracks, track) {
 			artifact.Tracks = append(artifact.Tracks, track)
@@ -51,24 +65,227 @@ func NewArtifact(name string, details *ArtifactDetails) *Artifact {
 		}
 	}
 
+	for _, c := range artifact.ChannelMap {
+		slices.SortFunc(c.Revisions, func(a, b *ArchRevision) int {
+			return cmp.Or(cmp.Compare(a.Base, b.Base), cmp.Compare(a.Architecture, b.Architecture))
+		})
+		slices.Sort(c.Bases)
+	}
+
+	for _, lib := range details.Libraries {
+		artifact.Libraries = append(artifact.Libraries, &Library{
+			Name:  lib.Get("library-name").String(),
+			ID:    lib.Get("library-id").String(),
+			API:   lib.Get("api").Int(),
+			Patch: lib.Get("patch").Int(),
+		})
+	}
+
 	return artifact
 }
 
-// Release represents a given Release of an artifact in a Canonical Store.
+// addToChannelMap adds a release to the entry in the channel map for its channel, creating the
+// entry if necessary. The channel's version is that of its newest revision.
+func (a *Artifact) addToChannelMap(release *Release) {
+	name := release.Track + "/" + release.Channel
+
+	index := slices.IndexFunc(a.ChannelMap, func(c *Channel) bool { return c.Name == name })
+	if index < 0 {
+		a.ChannelMap = append(a.ChannelMap, &Channel{Name: name, Track: release.Track, Risk: release.Channel})
+		index = len(a.ChannelMap) - 1
+	}
+
+	channel := a.ChannelMap[index]
+
+	if release.Revision > channel.revision {
+		channel.revision = release.Revision
+		channel.Version = release.Version
+	}
+
+	channel.Revisions = append(channel.Revisions, &ArchRevision{
+		Architecture: release.Architecture,
+		Base:         release.Base,
+		Revision:     release.Revision,
+		Version:      release.Version,
+	})
+
+	if release.Base != "" && !slices.Contains(channel.Bases, release.Base) {
+		channel.Bases = append(channel.Bases, release.Base)
+	}
+}
+
+// Release represents a given Release of an artifact in a Canonical Store. Snaps and charms are
+// released separately for each architecture, and charms also for each base.
 type Release struct {
-	Track     string `json:"track"`
-	Channel   string `json:"channel"`
-	Revision  int64  `json:"revision"`
-	Timestamp int64  `json:"timestamp"`
-	Base      string `json:"base"`
+	Track        string `json:"track"`
+	Channel      string `json:"channel"`
+	Revision     int64  `json:"revision"`
+	Timestamp    int64  `json:"timestamp"`
+	Base         string `json:"base"`
+	Architecture string `json:"architecture"`
+	Version      string `json:"version"`
+	Confinement  string `json:"confinement"`
+	Grade        string `json:"grade"`
+	Size         int64  `json:"size"`
+	// Bases are the bases supported by a charm's revision, and Resources are the resources, such
+	// as OCI images and files, released with it.
+	Bases     []*Base     `json:"bases"`
+	Resources []*Resource `json:"resources"`
+}
+
+// Base is an operating system that a charm's revision can be deployed on, such as Ubuntu 22.04.
+type Base struct {
+	Name         string `json:"name"`
+	Channel      string `json:"channel"`
+	Architecture string `json:"architecture"`
+}
+
+// Resource is a resource released with a charm's revision, such as an OCI image or a file.
+type Resource struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Revision int64  `json:"revision"`
+	Filename string `json:"filename"`
+}
+
+// Library is a charm library published by a charm, such as 'charms.mysql.v0.mysql'.
+type Library struct {
+	Name  string `json:"name"`
+	ID    string `json:"id"`
+	API   int64  `json:"api"`
+	Patch int64  `json:"patch"`
+}
+
+// Channel is a channel of an artifact, such as 'latest/stable', with the revision released to it
+// for each architecture.
+type Channel struct {
+	Name      string          `json:"name"`
+	Track     string          `json:"track"`
+	Risk      string          `json:"risk"`
+	Version   string          `json:"version"`
+	Bases     []string        `json:"bases"`
+	Revisions []*ArchRevision `json:"revisions"`
+
+	// revision is the newest revision in the channel, whose version is the channel's version.
+	revision int64
+}
+
+// ArchRevision is the revision released to a channel for a single architecture, and for charms, a
+// single base.
+type ArchRevision struct {
+	Architecture string `json:"architecture"`
+	Base         string `json:"base"`
+	Revision     int64  `json:"revision"`
+	Version      string `json:"version"`
 }
 
 // ArtifactDetails is used for storing the raw info fetched about an artifact from the store.
 type ArtifactDetails struct {
-	StoreURL     string
-	Tracks       []gjson.Result
-	Channels     []gjson.Result
-	ReleaseTimes []gjson.Result
-	Revisions    []gjson.Result
-	Bases        []gjson.Result
+	StoreURL      string
+	Tracks        []gjson.Result
+	Channels      []gjson.Result
+	ReleaseTimes  []gjson.Result
+	Revisions     []gjson.Result
+	Bases         []gjson.Result
+	Architectures []gjson.Result
+	Versions      []gjson.Result
+	// The following are only populated for snaps.
+	Confinements []gjson.Result
+	Grades       []gjson.Result
+	Sizes        []gjson.Result
+	// The following are only populated for charms.
+	RevisionBases []gjson.Result
+	Resources     []gjson.Result
+	Libraries     []gjson.Result
+	// LibrariesErr is the error that prevented a charm's libraries being fetched, in which case
+	// Libraries is empty. The rest of the details are still valid.
+	LibrariesErr error
+}
+
+// at returns the result at the given index, or an empty result if there isn't one, for details
+// that aren't reported for every artifact.
+func at(results []gjson.Result, index int) gjson.Result {
+	if index >= len(results) {
+		return gjson.Result{}
+	}
+
+	return results[index]
+}
+
+// baseName returns the name of a base, which is a string for snaps such as 'core22', and an object
+// for charms which is named such as 'ubuntu@22.04'.
+func baseName(base gjson.Result) string {
+	if !base.IsObject() {
+		return base.String()
+	}
+
+	return base.Get("name").String() + "@" + base.Get("channel").String()
+}
+
+// newBases returns the bases of a charm's revision, or nil for snaps.
+func newBases(bases gjson.Result) []*Base {
+	if !bases.Exists() {
+		return nil
+	}
+
+	result := []*Base{}
+
+	for _, b := range bases.Array() {
+		result = append(result, &Base{
+			Name:         b.Get("name").String(),
+			Channel:      b.Get("channel").String(),
+			Architecture: b.Get("architecture").String(),
+		})
+	}
+
+	return result
+}
+
+// newResources returns the resources released with a charm's revision, or nil for snaps.
+func newResources(resources gjson.Result) []*Resource {
+	if !resources.Exists() {
+		return nil
+	}
+
+	result := []*Resource{}
+
+	for _, r := range resources.Array() {
+		result = append(result, &Resource{
+			Name:     r.Get("name").String(),
+			Type:     r.Get("type").String(),
+			Revision: r.Get("revision").Int(),
+			Filename: r.Get("filename").String(),
+		})
+	}
+
+	return result
+}
+
+// fetchStoreResource queries a store or registry API, and returns the body of the response.
+func fetchStoreResource(ctx context.Context, apiURL string, header http.Header) (string, error) {
+	client := &http.Client{}
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+
+	for key, values := range header {
+		for _, v := range values {
+			req.Header.Add(key, v)
+		}
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return "", errors.New("failed to contact the store api")
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", errors.New("unexpected status code while fetching store resource")
+	}
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", errors.New("failed to read details about artifact from the store")
+	}
+
+	return string(resBody), nil
 }
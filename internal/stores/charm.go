@@ -2,44 +2,40 @@ package stores
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"io"
-	"net/http"
 
 	"github.com/tidwall/gjson"
 )
 
 // FetchCharmDetails fetches the Json representing charm information by querying the Charmhub API.
 func FetchCharmDetails(ctx context.Context, name string) (*ArtifactDetails, error) {
-	apiURL := fmt.Sprintf("http://api.snapcraft.io/v2/charms/info/%s?fields=channel-map,result.store-url", name)
+	apiURL := fmt.Sprintf("http://api.snapcraft.io/v2/charms/info/%s?fields=%s", name,
+		"channel-map,channel-map.revision.bases,channel-map.revision.version,channel-map.resources,result.store-url")
 
-	client := &http.Client{}
-	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
-
-	res, err := client.Do(req)
+	jsonBody, err := fetchStoreResource(ctx, apiURL, nil)
 	if err != nil {
-		return nil, errors.New("failed to contact the store api")
+		return nil, err
 	}
-	defer res.Body.Close()
 
-	if res.StatusCode != http.StatusOK {
-		return nil, errors.New("unexpected status code while fetching store resource")
+	// Libraries are published separately from the charm's revisions. They're only supplementary,
+	// so the charm's details are still returned if they can't be fetched.
+	libBody, libErr := fetchStoreResource(ctx, "http://api.snapcraft.io/v2/charms/libraries/"+name, nil)
+	if libErr != nil {
+		libErr = fmt.Errorf("error fetching charm libraries: %w", libErr)
 	}
 
-	// Parse the useful information from the response.
-	resBody, err := io.ReadAll(res.Body)
-	if err != nil {
-		return nil, errors.New("failed to read details about artifact from the store")
-	}
-
-	jsonBody := string(resBody)
-	storeURL := gjson.Get(jsonBody, "result.store-url").String()
-	tracks := gjson.Get(jsonBody, "channel-map.#.channel.track").Array()
-	channels := gjson.Get(jsonBody, "channel-map.#.channel.risk").Array()
-	releaseTimes := gjson.Get(jsonBody, "channel-map.#.channel.released-at").Array()
-	revisions := gjson.Get(jsonBody, "channel-map.#.revision.revision").Array()
-
-	// TODO: Implement base parsing for charms (like snaps).
-	return &ArtifactDetails{storeURL, tracks, channels, releaseTimes, revisions, []gjson.Result{}}, nil
+	return &ArtifactDetails{
+		StoreURL:      gjson.Get(jsonBody, "result.store-url").String(),
+		Tracks:        gjson.Get(jsonBody, "channel-map.#.channel.track").Array(),
+		Channels:      gjson.Get(jsonBody, "channel-map.#.channel.risk").Array(),
+		ReleaseTimes:  gjson.Get(jsonBody, "channel-map.#.channel.released-at").Array(),
+		Revisions:     gjson.Get(jsonBody, "channel-map.#.revision.revision").Array(),
+		Bases:         gjson.Get(jsonBody, "channel-map.#.channel.base").Array(),
+		Architectures: gjson.Get(jsonBody, "channel-map.#.channel.base.architecture").Array(),
+		Versions:      gjson.Get(jsonBody, "channel-map.#.revision.version").Array(),
+		RevisionBases: gjson.Get(jsonBody, "channel-map.#.revision.bases").Array(),
+		Resources:     gjson.Get(jsonBody, "channel-map.#.resources").Array(),
+		Libraries:     gjson.Get(libBody, "libraries").Array(),
+		LibrariesErr:  libErr,
+	}, nil
 }
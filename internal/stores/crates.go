@@ -0,0 +1,33 @@
+package stores
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/tidwall/gjson"
+)
+
+// FetchCrate fetches the versions of a Rust crate by querying the crates.io API.
+func FetchCrate(ctx context.Context, name string) (*Package, error) {
+	// According to: https://crates.io/data-access
+	// Requests to the API must identify the application with a User-Agent header.
+	header := http.Header{"User-Agent": {"releasegen (https://github.com/jnsgruk/releasegen)"}}
+
+	jsonBody, err := fetchStoreResource(ctx, fmt.Sprintf("https://crates.io/api/v1/crates/%s", name), header)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := []*PackageVersion{}
+
+	for _, v := range gjson.Get(jsonBody, "versions").Array() {
+		versions = append(versions, &PackageVersion{
+			Version:   v.Get("num").String(),
+			Timestamp: parseTimestamp(v.Get("created_at").String()),
+			Yanked:    v.Get("yanked").Bool(),
+		})
+	}
+
+	return newPackage(RegistryCrates, name, fmt.Sprintf("https://crates.io/crates/%s", name), versions), nil
+}
@@ -0,0 +1,173 @@
+package stores
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/tidwall/gjson"
+)
+
+// goProxyURL is the URL of the Go module proxy.
+const goProxyURL = "https://proxy.golang.org"
+
+// FetchGoModule fetches the versions of a Go module by querying the Go module proxy. The proxy
+// lists the module's versions, and the time of each is fetched separately, so only the times of
+// the newest versions, and of the wanted versions of the repository's releases and tags, are
+// fetched. Versions whose time can't be fetched are skipped.
+func FetchGoModule(ctx context.Context, module string, wanted []string) (*Package, error) {
+	escaped := escapeModulePath(module)
+
+	list, err := fetchStoreResource(ctx, fmt.Sprintf("%s/%s/@v/list", goProxyURL, escaped), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	listed := strings.Fields(list)
+	slices.SortFunc(listed, func(a, b string) int { return compareSemver(b, a) })
+
+	isWanted := func(version string) bool {
+		return slices.ContainsFunc(wanted, func(w string) bool { return normaliseVersion(w) == normaliseVersion(version) })
+	}
+
+	versions := []*PackageVersion{}
+
+	for i, version := range listed {
+		if i >= maxPackageVersions && !isWanted(version) {
+			continue
+		}
+
+		info, err := fetchStoreResource(ctx, fmt.Sprintf("%s/%s/@v/%s.info", goProxyURL, escaped, version), nil)
+		if err != nil {
+			log.Printf("failed to fetch go module version information for %s@%s: %s", module, version, err.Error())
+			continue
+		}
+
+		versions = append(versions, &PackageVersion{
+			Version:   version,
+			Timestamp: parseTimestamp(gjson.Get(info, "Time").String()),
+		})
+	}
+
+	return newPackage(RegistryGo, module, fmt.Sprintf("https://pkg.go.dev/%s", module), versions), nil
+}
+
+// escapeModulePath escapes a module path for the Go module proxy, which replaces each upper case
+// letter with an exclamation mark followed by the lower case letter.
+func escapeModulePath(module string) string {
+	var sb strings.Builder
+
+	for _, r := range module {
+		if unicode.IsUpper(r) {
+			sb.WriteRune('!')
+			r = unicode.ToLower(r)
+		}
+
+		sb.WriteRune(r)
+	}
+
+	return sb.String()
+}
+
+// semver is a parsed semantic version, such as 'v1.2.3-rc.1'. Build metadata, such as
+// '+incompatible', is ignored as it doesn't affect the order of versions.
+type semver struct {
+	core       [3]int
+	prerelease []string
+}
+
+// parseSemver parses a semantic version with a 'v' prefix, as listed by the Go module proxy.
+func parseSemver(version string) (semver, bool) {
+	v := semver{}
+
+	rest, ok := strings.CutPrefix(version, "v")
+	if !ok {
+		return v, false
+	}
+
+	rest, _, _ = strings.Cut(rest, "+")
+	core, prerelease, hasPrerelease := strings.Cut(rest, "-")
+
+	parts := strings.Split(core, ".")
+	if len(parts) != len(v.core) {
+		return v, false
+	}
+
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, false
+		}
+
+		v.core[i] = n
+	}
+
+	if hasPrerelease {
+		v.prerelease = strings.Split(prerelease, ".")
+	}
+
+	return v, true
+}
+
+// compareSemver compares two semantic versions, returning a negative number if a is older than
+// b, zero if they're equal, and a positive number if a is newer. Invalid versions are older than
+// every valid version.
+func compareSemver(a, b string) int {
+	va, okA := parseSemver(a)
+	vb, okB := parseSemver(b)
+
+	if !okA || !okB {
+		return cmp.Compare(boolInt(okA), boolInt(okB))
+	}
+
+	for i := range va.core {
+		if c := cmp.Compare(va.core[i], vb.core[i]); c != 0 {
+			return c
+		}
+	}
+
+	// A release is newer than any of its prereleases.
+	if len(va.prerelease) == 0 || len(vb.prerelease) == 0 {
+		return cmp.Compare(len(vb.prerelease), len(va.prerelease))
+	}
+
+	for i := range min(len(va.prerelease), len(vb.prerelease)) {
+		if c := comparePrereleaseIdentifier(va.prerelease[i], vb.prerelease[i]); c != 0 {
+			return c
+		}
+	}
+
+	return cmp.Compare(len(va.prerelease), len(vb.prerelease))
+}
+
+// comparePrereleaseIdentifier compares one dot separated identifier of two prereleases. Numeric
+// identifiers are compared numerically, and are older than alphanumeric identifiers.
+func comparePrereleaseIdentifier(a, b string) int {
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+
+	switch {
+	case errA == nil && errB == nil:
+		return cmp.Compare(na, nb)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// boolInt returns 1 for true and 0 for false.
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,53 @@
+package stores
+
+import "testing"
+
+func TestEscapeModulePath(t *testing.T) {
+	tests := []struct {
+		module string
+		want   string
+	}{
+		{"github.com/acme/tools", "github.com/acme/tools"},
+		{"github.com/Acme/Tools", "github.com/!acme/!tools"},
+		{"github.com/BurntSushi/toml", "github.com/!burnt!sushi/toml"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.module, func(t *testing.T) {
+			if got := escapeModulePath(tt.module); got != tt.want {
+				t.Errorf("escapeModulePath(%q) = %q, want %q", tt.module, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareSemver(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"v1.2.3", "v1.2.3", 0},
+		{"v1.2.3", "v1.2.4", -1},
+		{"v1.10.0", "v1.9.0", 1},
+		{"v2.0.0", "v1.99.99", 1},
+		{"v1.0.0-rc.1", "v1.0.0", -1},
+		{"v1.0.0", "v1.0.0-rc.1", 1},
+		{"v1.0.0-alpha", "v1.0.0-alpha.1", -1},
+		{"v1.0.0-alpha.1", "v1.0.0-alpha.beta", -1},
+		{"v1.0.0-beta.2", "v1.0.0-beta.11", -1},
+		{"v1.0.0-rc.1", "v1.0.0-beta", 1},
+		{"v2.0.0+incompatible", "v2.0.0", 0},
+		{"1.0.0", "v0.0.1", -1},
+		{"v1.0", "v0.0.1", -1},
+		{"latest", "master", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			if got := compareSemver(tt.a, tt.b); got != tt.want {
+				t.Errorf("compareSemver(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
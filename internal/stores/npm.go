@@ -0,0 +1,36 @@
+package stores
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// FetchNPMPackage fetches the versions of a JavaScript package by querying the npm registry.
+func FetchNPMPackage(ctx context.Context, name string) (*Package, error) {
+	// The slash in scoped package names, such as '@acme/tools', must be escaped.
+	apiURL := fmt.Sprintf("https://registry.npmjs.org/%s", strings.ReplaceAll(name, "/", "%2f"))
+
+	jsonBody, err := fetchStoreResource(ctx, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// The publication time of each version is listed separately from the versions, and includes
+	// versions that have since been unpublished.
+	times := gjson.Get(jsonBody, "time").Map()
+	versions := []*PackageVersion{}
+
+	gjson.Get(jsonBody, "versions").ForEach(func(version, _ gjson.Result) bool {
+		versions = append(versions, &PackageVersion{
+			Version:   version.String(),
+			Timestamp: parseTimestamp(times[version.String()].String()),
+		})
+
+		return true
+	})
+
+	return newPackage(RegistryNPM, name, fmt.Sprintf("https://www.npmjs.com/package/%s", name), versions), nil
+}
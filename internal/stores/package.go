@@ -0,0 +1,116 @@
+package stores
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// The package registries that packages can be fetched from.
+const (
+	RegistryPyPI   = "pypi"
+	RegistryCrates = "crates"
+	RegistryNPM    = "npm"
+	RegistryGo     = "go"
+)
+
+// maxPackageVersions is the number of a package's versions listed, newest first.
+const maxPackageVersions = 20
+
+// Registries are the supported package registries, in the order their packages are listed.
+//
+//nolint:gochecknoglobals
+var Registries = []string{RegistryPyPI, RegistryCrates, RegistryNPM, RegistryGo}
+
+// Package holds information about a library published to a package registry, such as PyPI.
+type Package struct {
+	Registry string            `json:"registry"`
+	Name     string            `json:"name"`
+	URL      string            `json:"url"`
+	Versions []*PackageVersion `json:"versions"`
+
+	// all holds every version fetched, newest first, of which only the newest are in Versions.
+	all []*PackageVersion
+}
+
+// PackageVersion is a single version of a package published to a registry.
+type PackageVersion struct {
+	Version   string `json:"version"`
+	Timestamp int64  `json:"timestamp"`
+	Yanked    bool   `json:"yanked"`
+}
+
+// FetchPackage fetches the details of a package from the named registry. Wanted are the versions
+// of the repository's releases and tags, which registries that need a request for each version's
+// details, such as the Go module proxy, fetch as well as the newest versions.
+func FetchPackage(ctx context.Context, registry, name string, wanted []string) (*Package, error) {
+	switch registry {
+	case RegistryPyPI:
+		return FetchPyPIPackage(ctx, name)
+	case RegistryCrates:
+		return FetchCrate(ctx, name)
+	case RegistryNPM:
+		return FetchNPMPackage(ctx, name)
+	case RegistryGo:
+		return FetchGoModule(ctx, name, wanted)
+	default:
+		return nil, fmt.Errorf("unknown package registry '%s'", registry)
+	}
+}
+
+// ID returns the identifier of the package, such as 'pypi:requests'.
+func (p *Package) ID() string {
+	return p.Registry + ":" + p.Name
+}
+
+// HasVersion reports whether the version was published to the registry and hasn't been yanked.
+// Versions are compared without any 'v' prefix, so the tag 'v1.2.0' matches the version '1.2.0'.
+// Every version that was fetched is checked, not only those listed in Versions.
+func (p *Package) HasVersion(version string) bool {
+	versions := p.all
+	if versions == nil {
+		versions = p.Versions
+	}
+
+	return slices.ContainsFunc(versions, func(v *PackageVersion) bool {
+		return !v.Yanked && normaliseVersion(v.Version) == normaliseVersion(version)
+	})
+}
+
+// newPackage returns a package listing its newest versions, newest first. Every version is kept
+// for matching against releases and tags.
+func newPackage(registry, name, url string, versions []*PackageVersion) *Package {
+	slices.SortStableFunc(versions, func(a, b *PackageVersion) int { return cmp.Compare(b.Timestamp, a.Timestamp) })
+
+	return &Package{
+		Registry: registry,
+		Name:     name,
+		URL:      url,
+		Versions: versions[:min(len(versions), maxPackageVersions)],
+		all:      versions,
+	}
+}
+
+// parseTimestamp parses the RFC 3339 timestamps used by package registries into Unix time, or
+// zero if the timestamp is invalid.
+func parseTimestamp(s string) int64 {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return 0
+	}
+
+	return t.Unix()
+}
+
+// normaliseVersion removes any 'v' prefix from a version, along with the path of tags such as
+// 'api/v1.2.0', which Go uses for modules in subdirectories.
+func normaliseVersion(version string) string {
+	if i := strings.LastIndex(version, "/"); i >= 0 {
+		version = version[i+1:]
+	}
+
+	return strings.TrimPrefix(strings.TrimPrefix(version, "v"), "V")
+}
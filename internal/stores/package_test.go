@@ -0,0 +1,61 @@
+package stores
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestNormaliseVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{"1.2.0", "1.2.0"},
+		{"v1.2.0", "1.2.0"},
+		{"V1.2.0", "1.2.0"},
+		{"api/v1.2.0", "1.2.0"},
+		{"tools/api/v1.2.0-rc.1", "1.2.0-rc.1"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			if got := normaliseVersion(tt.version); got != tt.want {
+				t.Errorf("normaliseVersion(%q) = %q, want %q", tt.version, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasVersion(t *testing.T) {
+	versions := []*PackageVersion{}
+	for i := range maxPackageVersions + 5 {
+		versions = append(versions, &PackageVersion{Version: fmt.Sprintf("v1.0.%d", i), Timestamp: int64(i)})
+	}
+
+	versions = append(versions, &PackageVersion{Version: "v0.9.0", Timestamp: -1, Yanked: true})
+	pkg := newPackage(RegistryGo, "example.com/acme", "", versions)
+
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{"v1.0.24", true},
+		{"1.0.0", true},
+		{"api/v1.0.0", true},
+		{"v0.9.0", false},
+		{"v2.0.0", false},
+	}
+
+	if len(pkg.Versions) != maxPackageVersions {
+		t.Fatalf("len(Versions) = %d, want %d", len(pkg.Versions), maxPackageVersions)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			if got := pkg.HasVersion(tt.version); got != tt.want {
+				t.Errorf("HasVersion(%q) = %t, want %t", tt.version, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package stores
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tidwall/gjson"
+)
+
+// FetchPyPIPackage fetches the versions of a Python package by querying the PyPI JSON API.
+func FetchPyPIPackage(ctx context.Context, name string) (*Package, error) {
+	jsonBody, err := fetchStoreResource(ctx, fmt.Sprintf("https://pypi.org/pypi/%s/json", name), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := []*PackageVersion{}
+
+	// Each version is released as one or more files, which may be uploaded at different times.
+	gjson.Get(jsonBody, "releases").ForEach(func(version, files gjson.Result) bool {
+		if len(files.Array()) == 0 {
+			return true
+		}
+
+		v := &PackageVersion{Version: version.String(), Yanked: true}
+
+		for _, f := range files.Array() {
+			ts := parseTimestamp(f.Get("upload_time_iso_8601").String())
+			if v.Timestamp == 0 || (ts != 0 && ts < v.Timestamp) {
+				v.Timestamp = ts
+			}
+
+			v.Yanked = v.Yanked && f.Get("yanked").Bool()
+		}
+
+		versions = append(versions, v)
+
+		return true
+	})
+
+	return newPackage(RegistryPyPI, name, gjson.Get(jsonBody, "info.package_url").String(), versions), nil
+}
@@ -2,9 +2,7 @@ package stores
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"io"
 	"net/http"
 
 	"github.com/tidwall/gjson"
@@ -13,32 +11,16 @@ import (
 // FetchSnapDetails fetches the Json representing charm information by querying the Snapcraft API.
 func FetchSnapDetails(ctx context.Context, name string) (*ArtifactDetails, error) {
 	// Query the Snapcraft API to obtain the charm information.
-	apiURL := fmt.Sprintf("http://api.snapcraft.io/v2/snaps/info/%s?fields=channel-map,revision,store-url,base", name)
+	apiURL := fmt.Sprintf("http://api.snapcraft.io/v2/snaps/info/%s?fields=channel-map,revision,store-url,base,version,confinement,grade,download", name)
 
-	client := &http.Client{}
-	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
 	// According to: https://api.snapcraft.io/docs/refresh.html
 	// The only valid 'Snap-Device-Series' to date is '16', and the
 	// header must be set in order for the request to be successful.
-	req.Header.Set("Snap-Device-Series", "16")
-
-	res, err := client.Do(req)
-	if err != nil {
-		return nil, errors.New("failed to contact the store api")
-	}
-	defer res.Body.Close()
-
-	if res.StatusCode != http.StatusOK {
-		return nil, errors.New("unexpected status code while fetching store resource")
-	}
-
-	// Parse the useful information from the response.
-	resBody, err := io.ReadAll(res.Body)
+	jsonBody, err := fetchStoreResource(ctx, apiURL, http.Header{"Snap-Device-Series": {"16"}})
 	if err != nil {
-		return nil, errors.New("failed to read details about artifact from the store")
+		return nil, err
 	}
 
-	jsonBody := string(resBody)
 	storeURL := gjson.Get(jsonBody, "snap.store-url").String()
 	tracks := gjson.Get(jsonBody, "channel-map.#.channel.track").Array()
 	channels := gjson.Get(jsonBody, "channel-map.#.channel.risk").Array()
@@ -46,5 +28,17 @@ func FetchSnapDetails(ctx context.Context, name string) (*ArtifactDetails, error
 	revisions := gjson.Get(jsonBody, "channel-map.#.revision").Array()
 	bases := gjson.Get(jsonBody, "channel-map.#.base").Array()
 
-	return &ArtifactDetails{storeURL, tracks, channels, releaseTimes, revisions, bases}, nil
+	return &ArtifactDetails{
+		StoreURL:      storeURL,
+		Tracks:        tracks,
+		Channels:      channels,
+		ReleaseTimes:  releaseTimes,
+		Revisions:     revisions,
+		Bases:         bases,
+		Architectures: gjson.Get(jsonBody, "channel-map.#.channel.architecture").Array(),
+		Versions:      gjson.Get(jsonBody, "channel-map.#.version").Array(),
+		Confinements:  gjson.Get(jsonBody, "channel-map.#.confinement").Array(),
+		Grades:        gjson.Get(jsonBody, "channel-map.#.grade").Array(),
+		Sizes:         gjson.Get(jsonBody, "channel-map.#.download.size").Array(),
+	}, nil
 }